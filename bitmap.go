@@ -0,0 +1,257 @@
+package go_redis
+
+import (
+	"context"
+	"strconv"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+type BitmapType struct {
+	db      *redis.Client
+	logger  i_logger.ILogger
+	prefix  keyPrefix
+	baseCtx context.Context
+}
+
+// Count、BitPos 的范围，Start、End 可以是负数（从末尾算起）。Bit 为 true 时按位计算（Redis 7），否则按字节
+type BitRange struct {
+	Start int64
+	End   int64
+	Bit   bool
+}
+
+// 按字节时不带 BYTE 参数，兼容 Redis 7 之前的版本
+func (r *BitRange) unit() string {
+	if r.Bit {
+		return redis.BitCountIndexBit
+	}
+	return ""
+}
+
+type BitOperation string
+
+const (
+	BitAnd BitOperation = "AND"
+	BitOr  BitOperation = "OR"
+	BitXor BitOperation = "XOR"
+	BitNot BitOperation = "NOT" // 只能有一个源 key
+)
+
+// 设置 offset 位的值，返回原来的值
+func (t *BitmapType) SetBit(key string, offset uint64, value bool) (old_ bool, err_ error) {
+	key = t.prefix.key(key)
+	bit := 0
+	if value {
+		bit = 1
+	}
+	result, err := t.db.SetBit(t.ctx("setbit"), key, int64(offset), bit).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "<key: %s> <offset: %d>", key, offset)
+	}
+	return result == 1, nil
+}
+
+// 获取 offset 位的值，key 不存在或超出长度时为 false
+func (t *BitmapType) GetBit(key string, offset uint64) (bool, error) {
+	key = t.prefix.key(key)
+	result, err := t.db.GetBit(t.ctx("getbit"), key, int64(offset)).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "<key: %s> <offset: %d>", key, offset)
+	}
+	return result == 1, nil
+}
+
+// 统计值为 1 的位数，bitRange 为 nil 时统计全部
+func (t *BitmapType) Count(key string, bitRange *BitRange) (uint64, error) {
+	key = t.prefix.key(key)
+	var bitCount *redis.BitCount
+	if bitRange != nil {
+		bitCount = &redis.BitCount{
+			Start: bitRange.Start,
+			End:   bitRange.End,
+			Unit:  bitRange.unit(),
+		}
+	}
+	result, err := t.db.BitCount(t.ctx("count"), key, bitCount).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return uint64(result), nil
+}
+
+// 返回第一个值为 bit 的位置，没有时返回 -1。bitRange 为 nil 时查找全部
+func (t *BitmapType) BitPos(key string, bit bool, bitRange *BitRange) (int64, error) {
+	key = t.prefix.key(key)
+	var value int8
+	if bit {
+		value = 1
+	}
+	var cmd *redis.IntCmd
+	switch {
+	case bitRange == nil:
+		cmd = t.db.BitPos(t.ctx("bitpos"), key, int64(value))
+	case bitRange.Bit:
+		cmd = t.db.BitPosSpan(t.ctx("bitpos"), key, value, bitRange.Start, bitRange.End, bitRange.unit())
+	default:
+		cmd = t.db.BitPos(t.ctx("bitpos"), key, int64(value), bitRange.Start, bitRange.End)
+	}
+	result, err := cmd.Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return result, nil
+}
+
+// 对 keys 做位运算，结果保存到 dest，返回 dest 的字节长度
+func (t *BitmapType) BitOp(op BitOperation, dest string, keys ...string) (int64, error) {
+	if len(keys) == 0 || (op == BitNot && len(keys) != 1) {
+		return 0, errors.Errorf("<op: %s> invalid number of keys: %d.", op, len(keys))
+	}
+	dest = t.prefix.key(dest)
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, t.prefix.key(key))
+	}
+	var cmd *redis.IntCmd
+	switch op {
+	case BitAnd:
+		cmd = t.db.BitOpAnd(t.ctx("bitop"), dest, prefixed...)
+	case BitOr:
+		cmd = t.db.BitOpOr(t.ctx("bitop"), dest, prefixed...)
+	case BitXor:
+		cmd = t.db.BitOpXor(t.ctx("bitop"), dest, prefixed...)
+	case BitNot:
+		cmd = t.db.BitOpNot(t.ctx("bitop"), dest, prefixed[0])
+	default:
+		return 0, errors.Errorf("<op: %s> unsupported.", op)
+	}
+	result, err := cmd.Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "<dest: %s> <keys: %v>", dest, prefixed)
+	}
+	return result, nil
+}
+
+// BITFIELD 的整数类型，例如 Signed(8) 是 i8，Unsigned(16) 是 u16
+type BitFieldEncoding string
+
+// 有符号整数，bits 为 1 到 64
+func Signed(bits int) BitFieldEncoding {
+	return BitFieldEncoding("i" + strconv.Itoa(bits))
+}
+
+// 无符号整数，bits 为 1 到 63
+func Unsigned(bits int) BitFieldEncoding {
+	return BitFieldEncoding("u" + strconv.Itoa(bits))
+}
+
+func (e BitFieldEncoding) validate() error {
+	if len(e) < 2 || (e[0] != 'i' && e[0] != 'u') {
+		return errors.Errorf("<encoding: %s> invalid.", e)
+	}
+	bits, err := strconv.Atoi(string(e[1:]))
+	if err != nil || bits < 1 || (e[0] == 'i' && bits > 64) || (e[0] == 'u' && bits > 63) {
+		return errors.Errorf("<encoding: %s> invalid.", e)
+	}
+	return nil
+}
+
+type BitFieldOverflow string
+
+const (
+	OverflowWrap BitFieldOverflow = "WRAP" // 回绕，默认
+	OverflowSat  BitFieldOverflow = "SAT"  // 饱和到最大值或最小值
+	OverflowFail BitFieldOverflow = "FAIL" // 溢出时不修改，结果的 Failed 为 true
+)
+
+type BitFieldResult struct {
+	Value  int64
+	Failed bool // OverflowFail 时发生了溢出
+}
+
+// BITFIELD 命令构造器，按调用顺序执行，每个 Get、Set、IncrBy 对应一个结果
+//
+//	results, err := instance.Bitmap.BitField("stats").
+//		Set(go_redis.Unsigned(8), 0, 200).
+//		Overflow(go_redis.OverflowSat).
+//		IncrBy(go_redis.Unsigned(8), 0, 100).
+//		Get(go_redis.Signed(4), 8).
+//		Exec()
+type BitFieldBuilder struct {
+	t    *BitmapType
+	key  string
+	args []any
+	err  error
+}
+
+// offset 是位偏移；以 # 开头的偏移（例如 "#2"）按 encoding 的宽度乘，用 BitFieldIndex 生成
+func (t *BitmapType) BitField(key string) *BitFieldBuilder {
+	return &BitFieldBuilder{
+		t:   t,
+		key: key,
+	}
+}
+
+// 第 index 个 encoding 宽度的整数的偏移，即 #index
+func BitFieldIndex(index int64) string {
+	return "#" + strconv.FormatInt(index, 10)
+}
+
+func (b *BitFieldBuilder) add(encoding BitFieldEncoding, args ...any) *BitFieldBuilder {
+	if b.err == nil {
+		b.err = encoding.validate()
+	}
+	b.args = append(b.args, args...)
+	return b
+}
+
+// offset 为 int64、uint64 位偏移或者 BitFieldIndex 的结果
+func (b *BitFieldBuilder) Get(encoding BitFieldEncoding, offset any) *BitFieldBuilder {
+	return b.add(encoding, "GET", string(encoding), offset)
+}
+
+func (b *BitFieldBuilder) Set(encoding BitFieldEncoding, offset any, value int64) *BitFieldBuilder {
+	return b.add(encoding, "SET", string(encoding), offset, value)
+}
+
+func (b *BitFieldBuilder) IncrBy(encoding BitFieldEncoding, offset any, increment int64) *BitFieldBuilder {
+	return b.add(encoding, "INCRBY", string(encoding), offset, increment)
+}
+
+// 之后的 Set、IncrBy 使用的溢出策略
+func (b *BitFieldBuilder) Overflow(overflow BitFieldOverflow) *BitFieldBuilder {
+	b.args = append(b.args, "OVERFLOW", string(overflow))
+	return b
+}
+
+func (b *BitFieldBuilder) Exec() ([]BitFieldResult, error) {
+	key := b.t.prefix.key(b.key)
+	if b.err != nil {
+		return nil, errors.WithMessagef(b.err, "<key: %s>", key)
+	}
+	args := append([]any{"BITFIELD", key}, b.args...)
+	values, err := b.t.db.Do(b.t.ctx("bitfield"), args...).Slice()
+	if err != nil {
+		return nil, errors.Wrapf(err, "<key: %s>", key)
+	}
+	results := make([]BitFieldResult, 0, len(values))
+	for i, value := range values {
+		if value == nil {
+			results = append(results, BitFieldResult{Failed: true})
+			continue
+		}
+		n, ok := value.(int64)
+		if !ok {
+			return nil, errors.Errorf("<key: %s> <index: %d> unexpected result %T.", key, i, value)
+		}
+		results = append(results, BitFieldResult{Value: n})
+	}
+	return results, nil
+}
+
+func (t *BitmapType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "bitmap."+operation)
+}
@@ -0,0 +1,156 @@
+package go_redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2/server"
+	go_test_ "github.com/pefish/go-test"
+)
+
+func TestBitmapType_Bits(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	old, err := instance.Bitmap.SetBit("bits", 7, true)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, old)
+	old, err = instance.Bitmap.SetBit("bits", 7, true)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, old)
+	_, err = instance.Bitmap.SetBit("bits", 9, true)
+	go_test_.Equal(t, nil, err)
+
+	bit, err := instance.Bitmap.GetBit("bits", 9)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, bit)
+	bit, err = instance.Bitmap.GetBit("bits", 1000)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, bit)
+
+	count, err := instance.Bitmap.Count("bits", nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(2), count)
+	count, err = instance.Bitmap.Count("bits", &BitRange{Start: 1, End: -1})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(1), count)
+
+	pos, err := instance.Bitmap.BitPos("bits", true, nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(7), pos)
+	pos, err = instance.Bitmap.BitPos("bits", true, &BitRange{Start: 1, End: 1})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(9), pos)
+	pos, err = instance.Bitmap.BitPos("missing", true, nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(-1), pos)
+}
+
+func TestBitmapType_BitOp(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	go_test_.Equal(t, nil, server.Set("a", "\xf0"))
+	go_test_.Equal(t, nil, server.Set("b", "\x3c"))
+
+	length, err := instance.Bitmap.BitOp(BitAnd, "and", "a", "b")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(1), length)
+	value, _ := server.Get("and")
+	go_test_.Equal(t, "\x30", value)
+
+	_, err = instance.Bitmap.BitOp(BitXor, "xor", "a", "b")
+	go_test_.Equal(t, nil, err)
+	value, _ = server.Get("xor")
+	go_test_.Equal(t, "\xcc", value)
+
+	_, err = instance.Bitmap.BitOp(BitNot, "not", "a")
+	go_test_.Equal(t, nil, err)
+	value, _ = server.Get("not")
+	go_test_.Equal(t, "\x0f", value)
+
+	_, err = instance.Bitmap.BitOp(BitNot, "not", "a", "b")
+	go_test_.NotEqual(t, nil, err)
+	_, err = instance.Bitmap.BitOp(BitOr, "or")
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestBitmapType_BitUnit(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	var mu sync.Mutex
+	args := make(map[string][]any)
+	instance.AddHook(Hook{
+		Name: "capture",
+		Before: func(ctx context.Context, info *CommandInfo) context.Context {
+			mu.Lock()
+			defer mu.Unlock()
+			args[info.Name] = info.Args
+			return ctx
+		},
+	})
+	// miniredis 不支持 BIT 单位，这里只检查发出的参数
+	instance.Bitmap.Count("bits", &BitRange{Start: 0, End: 15, Bit: true})
+	instance.Bitmap.BitPos("bits", false, &BitRange{Start: 0, End: 15, Bit: true})
+	mu.Lock()
+	defer mu.Unlock()
+	go_test_.Equal(t, "BIT", args["bitcount"][len(args["bitcount"])-1])
+	go_test_.Equal(t, "BIT", args["bitpos"][len(args["bitpos"])-1])
+}
+
+func TestBitmapType_BitField(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	var received []string
+	// miniredis 不支持 BITFIELD，这里记录参数，并按子命令返回固定结果，FAIL 溢出之后的 INCRBY 返回 nil
+	go_test_.Equal(t, nil, m.Server().Register("BITFIELD", func(c *server.Peer, cmd string, args []string) {
+		received = args
+		results := make([]string, 0)
+		fail := false
+		for i := 1; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "OVERFLOW":
+				fail = strings.EqualFold(args[i+1], "FAIL")
+				i++
+			case "GET":
+				results = append(results, "get")
+				i += 2
+			case "SET", "INCRBY":
+				if fail {
+					results = append(results, "nil")
+				} else {
+					results = append(results, "set")
+				}
+				i += 3
+			}
+		}
+		c.WriteLen(len(results))
+		for _, result := range results {
+			switch result {
+			case "nil":
+				c.WriteNull()
+			case "get":
+				c.WriteInt(-3)
+			default:
+				c.WriteInt(200)
+			}
+		}
+	}))
+
+	results, err := instance.WithPrefix("app:").Bitmap.BitField("stats").
+		Set(Unsigned(8), 0, 200).
+		Get(Signed(4), BitFieldIndex(2)).
+		Overflow(OverflowFail).
+		IncrBy(Unsigned(8), int64(0), 100).
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, len(results))
+	go_test_.Equal(t, BitFieldResult{Value: 200}, results[0])
+	go_test_.Equal(t, BitFieldResult{Value: -3}, results[1])
+	go_test_.Equal(t, BitFieldResult{Failed: true}, results[2])
+	go_test_.Equal(t, "app:stats SET u8 0 200 GET i4 #2 OVERFLOW FAIL INCRBY u8 0 100", strings.Join(received, " "))
+
+	_, err = instance.Bitmap.BitField("stats").Get(Unsigned(64), 0).Exec()
+	go_test_.NotEqual(t, nil, err)
+	_, err = instance.Bitmap.BitField("stats").Get(Signed(0), 0).Exec()
+	go_test_.NotEqual(t, nil, err)
+	_, err = instance.Bitmap.BitField("stats").Get("x8", 0).Exec()
+	go_test_.NotEqual(t, nil, err)
+}
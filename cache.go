@@ -0,0 +1,169 @@
+package go_redis
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// 数据源中不存在该值。loader 返回它时会触发负缓存
+var ErrNotFound = errors.New("redis: not found")
+
+// 缓存未命中时从数据源加载值，数据源中不存在时返回 ErrNotFound
+type LoaderFunc[T any] func(ctx context.Context) (T, error)
+
+type CacheOptions struct {
+	NegativeTTL time.Duration // 数据源中不存在时缓存“不存在”的时间，0 表示不做负缓存
+	Beta        float64       // XFetch 提前刷新系数，0 表示不提前刷新，一般取 1，越大越提前
+}
+
+type Cache[T any] struct {
+	redis   *RedisType
+	options CacheOptions
+	group   singleflight.Group
+}
+
+type cacheEntry[T any] struct {
+	Value    T     `json:"v,omitempty"`
+	NotFound bool  `json:"n,omitempty"`
+	Delta    int64 `json:"d"` // 上次加载耗时，毫秒
+	Expiry   int64 `json:"e"` // 过期时间，unix 毫秒
+}
+
+// 创建 cache-aside 缓存，值以 JSON 存储在 String 类型的 key 中
+func NewCache[T any](redis *RedisType, options *CacheOptions) *Cache[T] {
+	c := &Cache[T]{
+		redis: redis,
+	}
+	if options != nil {
+		c.options = *options
+	}
+	return c
+}
+
+// 先读缓存，未命中时调用 loader 加载并写入缓存，同一进程内同一个 key 的并发加载只会执行一次。
+// loader 返回 ErrNotFound 时，如果配置了 NegativeTTL，会把“不存在”也缓存起来，之后直接返回 ErrNotFound
+func (c *Cache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader LoaderFunc[T]) (T, error) {
+	var zero T
+
+	entry, ok, err := c.get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	if ok {
+		if c.shouldRefreshEarly(entry) {
+			// 直接返回当前值，后台刷新
+			c.group.DoChan(key, func() (any, error) {
+				return c.load(context.WithoutCancel(ctx), key, ttl, loader)
+			})
+		}
+		if entry.NotFound {
+			return zero, ErrNotFound
+		}
+		return entry.Value, nil
+	}
+
+	// 加载不受某一个调用方的取消影响，否则它取消时所有等待同一个 key 的调用方都会失败；每个调用方按自己的 ctx 等待
+	ch := c.group.DoChan(key, func() (any, error) {
+		return c.load(context.WithoutCancel(ctx), key, ttl, loader)
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		// T 是接口类型且 loader 返回 nil 时，singleflight 返回的是无类型的 nil
+		value, _ := result.Val.(T)
+		return value, nil
+	case <-ctx.Done():
+		return zero, errors.Wrapf(ctx.Err(), "<key: %s>", c.redis.prefix.key(key))
+	}
+}
+
+// 删除缓存，下次读取时重新加载
+func (c *Cache[T]) Invalidate(ctx context.Context, key string) error {
+	key = c.redis.prefix.key(key)
+	if err := c.redis.Db.Del(withOperation(ctx, "cache.invalidate"), key).Err(); err != nil {
+		return errors.Wrapf(err, "<key: %s>", key)
+	}
+	return nil
+}
+
+func (c *Cache[T]) get(ctx context.Context, key string) (*cacheEntry[T], bool, error) {
+	key = c.redis.prefix.key(key)
+	data, err := c.redis.Db.Get(withOperation(ctx, "cache.get"), key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "<key: %s>", key)
+	}
+	var entry cacheEntry[T]
+	if err := json.Unmarshal(data, &entry); err != nil {
+		// 格式不对当作未命中，重新加载后会被覆盖
+		c.redis.logger.WarnF(`Redis cache decode failed, reload. key: %s, err: %s`, key, err)
+		return nil, false, nil
+	}
+	return &entry, true, nil
+}
+
+func (c *Cache[T]) load(ctx context.Context, key string, ttl time.Duration, loader LoaderFunc[T]) (T, error) {
+	var zero T
+
+	start := time.Now()
+	value, err := loader(ctx)
+	delta := time.Since(start)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			if c.options.NegativeTTL > 0 {
+				if err := c.set(ctx, key, &cacheEntry[T]{NotFound: true}, delta, c.options.NegativeTTL); err != nil {
+					return zero, err
+				}
+			}
+			return zero, ErrNotFound
+		}
+		return zero, errors.Wrapf(err, "<key: %s> load failed.", key)
+	}
+	if err := c.set(ctx, key, &cacheEntry[T]{Value: value}, delta, ttl); err != nil {
+		return zero, err
+	}
+	return value, nil
+}
+
+func (c *Cache[T]) set(ctx context.Context, key string, entry *cacheEntry[T], delta time.Duration, ttl time.Duration) error {
+	key = c.redis.prefix.key(key)
+	entry.Delta = delta.Milliseconds()
+	if ttl > 0 {
+		entry.Expiry = time.Now().Add(ttl).UnixMilli()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrapf(err, "<key: %s> encode failed.", key)
+	}
+	if err := c.redis.Db.Set(withOperation(ctx, "cache.set"), key, data, ttl).Err(); err != nil {
+		return errors.Wrapf(err, "<key: %s>", key)
+	}
+	return nil
+}
+
+// 测试中替换成固定值
+var cacheRandFloat64 = rand.Float64
+
+// XFetch：now - delta * beta * ln(rand) >= expiry 时提前刷新，加载越慢、越接近过期，越容易触发
+func (c *Cache[T]) shouldRefreshEarly(entry *cacheEntry[T]) bool {
+	if c.options.Beta <= 0 || entry.Expiry == 0 {
+		return false
+	}
+	delta := float64(entry.Delta)
+	if delta <= 0 {
+		delta = 1
+	}
+	gap := -delta * c.options.Beta * math.Log(1-cacheRandFloat64())
+	return float64(time.Now().UnixMilli())+gap >= float64(entry.Expiry)
+}
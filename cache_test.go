@@ -0,0 +1,165 @@
+package go_redis
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_test_ "github.com/pefish/go-test"
+	"github.com/pkg/errors"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	cache := NewCache[string](instance, nil)
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.GetOrLoad(context.Background(), "test_cache", time.Minute, loader)
+			go_test_.Equal(t, nil, err)
+			go_test_.Equal(t, "", v)
+		}()
+	}
+	wg.Wait()
+	go_test_.Equal(t, int32(1), calls.Load())
+
+	// 空字符串也是命中
+	_, err := cache.GetOrLoad(context.Background(), "test_cache", time.Minute, loader)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int32(1), calls.Load())
+}
+
+func TestCache_NegativeTTL(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	cache := NewCache[int](instance, &CacheOptions{
+		NegativeTTL: time.Second,
+	})
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 0, ErrNotFound
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetOrLoad(context.Background(), "test_cache_neg", time.Minute, loader)
+		go_test_.Equal(t, true, errors.Is(err, ErrNotFound))
+	}
+	go_test_.Equal(t, int32(1), calls.Load())
+
+	server.FastForward(2 * time.Second)
+	_, err := cache.GetOrLoad(context.Background(), "test_cache_neg", time.Minute, loader)
+	go_test_.Equal(t, true, errors.Is(err, ErrNotFound))
+	go_test_.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_NilInterface(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	cache := NewCache[error](instance, nil)
+
+	v, err := cache.GetOrLoad(context.Background(), "test_cache_nil", time.Minute, func(ctx context.Context) (error, error) {
+		return nil, nil
+	})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, nil, v)
+}
+
+func TestCache_CallerCancel(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	cache := NewCache[string](instance, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (string, error) {
+		close(started)
+		<-release
+		// 第一个调用方已经取消，加载不能跟着失败
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "value", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cache.GetOrLoad(ctx, "test_cache_cancel", time.Minute, loader)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan string, 1)
+	go func() {
+		v, err := cache.GetOrLoad(context.Background(), "test_cache_cancel", time.Minute, loader)
+		go_test_.Equal(t, nil, err)
+		second <- v
+	}()
+
+	cancel()
+	go_test_.Equal(t, true, errors.Is(<-firstErr, context.Canceled))
+	close(release)
+	go_test_.Equal(t, "value", <-second)
+}
+
+func TestCache_RefreshEarly(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	cache := NewCache[int](instance, &CacheOptions{Beta: 1})
+	// -ln(1 - 0.999999) ≈ 13.8，加载耗时按 1ms 算时提前约 14ms 刷新
+	old := cacheRandFloat64
+	cacheRandFloat64 = func() float64 { return 0.999999 }
+	t.Cleanup(func() { cacheRandFloat64 = old })
+
+	var calls atomic.Int32
+	loader := func(ctx context.Context) (int, error) {
+		return int(calls.Add(1)), nil
+	}
+
+	v, err := cache.GetOrLoad(context.Background(), "test_cache_xfetch", time.Hour, loader)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 1, v)
+
+	// 离过期还很远，不刷新
+	v, err = cache.GetOrLoad(context.Background(), "test_cache_xfetch", time.Hour, loader)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 1, v)
+	go_test_.Equal(t, int32(1), calls.Load())
+
+	// 把过期时间改到 5ms 后，key 本身还在，返回旧值并在后台刷新
+	key := "test_cache_xfetch"
+	var entry cacheEntry[int]
+	data, _ := server.Get(key)
+	go_test_.Equal(t, nil, json.Unmarshal([]byte(data), &entry))
+	entry.Expiry = time.Now().Add(5 * time.Millisecond).UnixMilli()
+	entry.Delta = 1
+	raw, _ := json.Marshal(entry)
+	go_test_.Equal(t, nil, server.Set(key, string(raw)))
+
+	v, err = cache.GetOrLoad(context.Background(), key, time.Hour, loader)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 1, v)
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	go_test_.Equal(t, int32(2), calls.Load())
+	for time.Now().Before(deadline) {
+		if v, _ = cache.GetOrLoad(context.Background(), key, time.Hour, loader); v == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go_test_.Equal(t, 2, v)
+}
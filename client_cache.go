@@ -0,0 +1,281 @@
+package go_redis
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const invalidateChannel = `__redis__:invalidate`
+
+type ClientCacheConfig struct {
+	MaxEntries int // 本地最多缓存多少条，超过后按 LRU 淘汰，默认 10000
+}
+
+type ClientCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Flushes       uint64
+	Entries       int
+}
+
+type clientCacheEntry struct {
+	key      string // 带类型前缀的本地 key
+	redisKey string
+	value    any
+}
+
+// 客户端缓存。缓存未命中时通过 trackingDb 读取，服务端会记住这个连接读过的 key，
+// key 被修改时把失效通知转发（REDIRECT）到 invalidateDb 上订阅的 __redis__:invalidate 频道
+type clientCache struct {
+	logger     i_logger.ILogger
+	maxEntries int
+
+	mu     sync.Mutex
+	ll     *list.List
+	items  map[string]*list.Element
+	byKey  map[string][]string // redis key -> 本地 key
+	seq    uint64              // 每次失效/清空都会递增，用于丢弃读取期间已经失效的结果
+	stats  ClientCacheStats
+	closed atomic.Bool
+
+	trackingMu      sync.Mutex // 保护 trackingDb 的替换和关闭
+	trackingDb      atomic.Pointer[redis.Client]
+	trackingOptions *redis.Options
+	trackingHooks   []redis.Hook
+	invalidateDb    *redis.Client
+	pubsub          *redis.PubSub
+	redirectId      atomic.Int64
+}
+
+func newClientCache(logger i_logger.ILogger, maxEntries int) *clientCache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &clientCache{
+		logger:     logger,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		byKey:      make(map[string][]string),
+	}
+}
+
+// hooks 会加到追踪连接上，重新创建追踪连接时同样会加上
+func (c *clientCache) start(options *redis.Options, hooks ...redis.Hook) error {
+	invalidateOptions := *options
+	invalidateOptions.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return err
+		}
+		c.redirectId.Store(id)
+		return nil
+	}
+	c.invalidateDb = redis.NewClient(&invalidateOptions)
+	c.pubsub = c.invalidateDb.Subscribe(context.Background(), invalidateChannel)
+	// 等订阅成功，确保拿到了 redirect 的 client id
+	if _, err := c.pubsub.Receive(context.Background()); err != nil {
+		c.close()
+		return errors.Wrap(err, "client cache subscribe failed")
+	}
+
+	// 连接池大小和主库一致，每个连接建立时各自开启追踪，未命中的读取不会排队等同一个连接
+	trackingOptions := *options
+	trackingOptions.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		// 新连接可能是替换断开的连接，断开的连接上读过的 key 不会再收到失效通知，本地缓存必须清空
+		c.flush()
+		return cn.Do(ctx, "CLIENT", "TRACKING", "ON", "REDIRECT", c.redirectId.Load()).Err()
+	}
+	c.trackingOptions = &trackingOptions
+	c.trackingHooks = hooks
+	c.trackingDb.Store(c.newTrackingDb())
+	if err := c.tracking().Ping(context.Background()).Err(); err != nil {
+		c.close()
+		return errors.Wrap(err, "client cache tracking failed")
+	}
+
+	go c.receive()
+	return nil
+}
+
+func (c *clientCache) newTrackingDb() *redis.Client {
+	client := redis.NewClient(c.trackingOptions)
+	for _, hook := range c.trackingHooks {
+		client.AddHook(hook)
+	}
+	return client
+}
+
+// 缓存未命中时读取用的连接
+func (c *clientCache) tracking() *redis.Client {
+	return c.trackingDb.Load()
+}
+
+// 订阅连接重连后 client id 变了，连接池里每个追踪连接都还转发到旧的 id，
+// 只能整个替换掉，新连接建立时在 OnConnect 中转发到新的 id
+func (c *clientCache) renewTracking() {
+	c.trackingMu.Lock()
+	defer c.trackingMu.Unlock()
+	if c.closed.Load() {
+		return
+	}
+	old := c.trackingDb.Swap(c.newTrackingDb())
+	if err := old.Close(); err != nil {
+		c.logger.WarnF(`Redis client cache close tracking failed. err: %s`, err)
+	}
+}
+
+func (c *clientCache) receive() {
+	for {
+		msg, err := c.pubsub.Receive(context.Background())
+		if err != nil {
+			if c.closed.Load() {
+				return
+			}
+			// 连接断开或者收到无法解析的通知（例如 FLUSHALL 时的空通知），都清空
+			c.logger.WarnF(`Redis client cache receive failed, flush. err: %s`, err)
+			c.flush()
+			time.Sleep(time.Second)
+			continue
+		}
+		c.handle(msg)
+	}
+}
+
+func (c *clientCache) handle(msg any) {
+	switch m := msg.(type) {
+	case *redis.Subscription:
+		// 先替换再清空，用旧连接读到的结果拿的是清空前的 seq，不会写入缓存
+		c.renewTracking()
+		c.flush()
+	case *redis.Message:
+		if m.Channel != invalidateChannel {
+			return
+		}
+		switch {
+		case m.PayloadSlice != nil:
+			c.invalidate(m.PayloadSlice...)
+		case m.Payload != ``:
+			c.invalidate(m.Payload)
+		default:
+			c.flush()
+		}
+	}
+}
+
+func (c *clientCache) close() {
+	if !c.closed.CompareAndSwap(false, true) {
+		return
+	}
+	if c.pubsub != nil {
+		c.pubsub.Close()
+	}
+	if c.invalidateDb != nil {
+		c.invalidateDb.Close()
+	}
+	c.trackingMu.Lock()
+	defer c.trackingMu.Unlock()
+	if trackingDb := c.tracking(); trackingDb != nil {
+		trackingDb.Close()
+	}
+}
+
+func (c *clientCache) get(key string) (any, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		c.stats.Hits++
+		return el.Value.(*clientCacheEntry).value, c.seq, true
+	}
+	c.stats.Misses++
+	return nil, c.seq, false
+}
+
+// seq 是读取前 get 返回的序号，读取期间发生过失效就不写入
+func (c *clientCache) set(key string, redisKey string, value any, seq uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if seq != c.seq {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value.(*clientCacheEntry).value = value
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&clientCacheEntry{
+		key:      key,
+		redisKey: redisKey,
+		value:    value,
+	})
+	c.byKey[redisKey] = append(c.byKey[redisKey], key)
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *clientCache) invalidate(redisKeys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	for _, redisKey := range redisKeys {
+		keys := append([]string(nil), c.byKey[redisKey]...)
+		for _, key := range keys {
+			if el, ok := c.items[key]; ok {
+				c.removeElement(el)
+				c.stats.Invalidations++
+			}
+		}
+	}
+}
+
+func (c *clientCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.byKey = make(map[string][]string)
+	c.stats.Flushes++
+}
+
+func (c *clientCache) removeElement(el *list.Element) {
+	entry := c.ll.Remove(el).(*clientCacheEntry)
+	delete(c.items, entry.key)
+	keys := c.byKey[entry.redisKey]
+	for i, key := range keys {
+		if key == entry.key {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if len(keys) == 0 {
+		delete(c.byKey, entry.redisKey)
+	} else {
+		c.byKey[entry.redisKey] = keys
+	}
+}
+
+func (c *clientCache) getStats() ClientCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.ll.Len()
+	return stats
+}
+
+func clientCacheKey(kind string, key string) string {
+	return fmt.Sprintf("%s:%s", kind, key)
+}
@@ -0,0 +1,209 @@
+package go_redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestClientCache_LRU(t *testing.T) {
+	cache := newClientCache(&i_logger.DefaultLogger, 2)
+
+	_, seq, _ := cache.get("string:a")
+	cache.set("string:a", "a", "1", seq)
+	cache.set("hash:a", "a", map[string]string{"f": "1"}, seq)
+	cache.set("string:b", "b", "2", seq)
+	go_test_.Equal(t, 2, cache.getStats().Entries)
+	go_test_.Equal(t, uint64(1), cache.getStats().Evictions)
+
+	_, _, ok := cache.get("string:a")
+	go_test_.Equal(t, false, ok)
+
+	// 同一个 redis key 的所有本地缓存一起失效
+	cache.invalidate("a")
+	_, _, ok = cache.get("hash:a")
+	go_test_.Equal(t, false, ok)
+	v, _, ok := cache.get("string:b")
+	go_test_.Equal(t, true, ok)
+	go_test_.Equal(t, "2", v)
+}
+
+func TestClientCache_StaleSet(t *testing.T) {
+	cache := newClientCache(&i_logger.DefaultLogger, 10)
+
+	_, seq, _ := cache.get("string:a")
+	// 读取期间收到了失效通知，读到的值不能写入
+	cache.invalidate("a")
+	cache.set("string:a", "a", "old", seq)
+	_, _, ok := cache.get("string:a")
+	go_test_.Equal(t, false, ok)
+
+	cache.flush()
+	stats := cache.getStats()
+	go_test_.Equal(t, uint64(1), stats.Flushes)
+	go_test_.Equal(t, uint64(2), stats.Misses)
+}
+
+func TestClientCache_Handle(t *testing.T) {
+	server := miniredis.RunT(t)
+	cache := newClientCache(&i_logger.DefaultLogger, 10)
+	cache.trackingOptions = &redis.Options{Addr: server.Addr()}
+	cache.trackingDb.Store(cache.newTrackingDb())
+	t.Cleanup(cache.close)
+
+	fill := func() {
+		_, seq, _ := cache.get("string:a")
+		cache.set("string:a", "a", "1", seq)
+		cache.set("string:b", "b", "2", seq)
+		cache.set("string:c", "c", "3", seq)
+	}
+	fill()
+
+	// RESP3 推送的失效通知是 key 数组
+	cache.handle(&redis.Message{Channel: invalidateChannel, PayloadSlice: []string{"a", "b"}})
+	_, _, ok := cache.get("string:a")
+	go_test_.Equal(t, false, ok)
+	_, _, ok = cache.get("string:b")
+	go_test_.Equal(t, false, ok)
+	_, _, ok = cache.get("string:c")
+	go_test_.Equal(t, true, ok)
+
+	cache.handle(&redis.Message{Channel: invalidateChannel, Payload: "c"})
+	_, _, ok = cache.get("string:c")
+	go_test_.Equal(t, false, ok)
+	go_test_.Equal(t, uint64(3), cache.getStats().Invalidations)
+
+	// 其他频道的消息忽略
+	fill()
+	cache.handle(&redis.Message{Channel: "other", Payload: "a"})
+	go_test_.Equal(t, 3, cache.getStats().Entries)
+
+	// FLUSHALL 时的通知没有 key，全部清空
+	cache.handle(&redis.Message{Channel: invalidateChannel})
+	go_test_.Equal(t, 0, cache.getStats().Entries)
+	go_test_.Equal(t, uint64(1), cache.getStats().Flushes)
+
+	// 重新订阅后同样清空
+	fill()
+	cache.handle(&redis.Subscription{Kind: "subscribe", Channel: invalidateChannel, Count: 1})
+	go_test_.Equal(t, 0, cache.getStats().Entries)
+	go_test_.Equal(t, uint64(2), cache.getStats().Flushes)
+}
+
+// 在 miniredis 上模拟 CLIENT ID 和 CLIENT TRACKING，记录每个连接转发到的 client id
+type fakeTracking struct {
+	mu         sync.Mutex
+	nextId     int
+	ids        map[*server.Peer]int
+	redirects  map[*server.Peer]string
+	subscriber *server.Peer
+	kick       bool // 订阅连接下一条命令执行完后断开
+}
+
+func registerFakeTracking(m *miniredis.Miniredis) *fakeTracking {
+	f := &fakeTracking{
+		ids:       make(map[*server.Peer]int),
+		redirects: make(map[*server.Peer]string),
+	}
+	m.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch {
+		case cmd == "CLIENT" && len(args) == 1 && strings.EqualFold(args[0], "ID"):
+			f.nextId++
+			f.ids[c] = f.nextId
+			c.WriteInt(f.nextId)
+			return true
+		case cmd == "CLIENT" && len(args) == 4 && strings.EqualFold(args[0], "TRACKING"):
+			f.redirects[c] = args[3]
+			c.OnDisconnect(func() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				delete(f.redirects, c)
+			})
+			c.WriteOK()
+			return true
+		case cmd == "SUBSCRIBE":
+			f.subscriber = c
+		case c == f.subscriber && f.kick:
+			f.kick = false
+			c.Close()
+		}
+		return false
+	})
+	return f
+}
+
+// 还连着的追踪连接各自转发到的 client id
+func (f *fakeTracking) liveRedirects() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	results := make([]string, 0, len(f.redirects))
+	for _, redirect := range f.redirects {
+		results = append(results, redirect)
+	}
+	return results
+}
+
+func TestClientCache_Resubscribe(t *testing.T) {
+	m := miniredis.RunT(t)
+	tracking := registerFakeTracking(m)
+	cache := newClientCache(&i_logger.DefaultLogger, 10)
+	go_test_.Equal(t, nil, cache.start(&redis.Options{Addr: m.Addr(), PoolSize: 4}))
+	t.Cleanup(cache.close)
+
+	// 让连接池里有多个追踪连接
+	holdConns := func() {
+		conns := make([]*redis.Conn, 0, 3)
+		for i := 0; i < 3; i++ {
+			conn := cache.tracking().Conn()
+			go_test_.Equal(t, nil, conn.Ping(context.Background()).Err())
+			conns = append(conns, conn)
+		}
+		for _, conn := range conns {
+			go_test_.Equal(t, nil, conn.Close())
+		}
+	}
+	holdConns()
+	oldId := strconv.FormatInt(cache.redirectId.Load(), 10)
+	go_test_.Equal(t, []string{oldId, oldId, oldId}, tracking.liveRedirects())
+
+	// 断开订阅连接，重连后 client id 变了
+	tracking.mu.Lock()
+	tracking.kick = true
+	tracking.mu.Unlock()
+	go_test_.Equal(t, nil, cache.pubsub.Ping(context.Background()))
+	deadline := time.Now().Add(5 * time.Second)
+	for strconv.FormatInt(cache.redirectId.Load(), 10) == oldId && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	newId := strconv.FormatInt(cache.redirectId.Load(), 10)
+	go_test_.NotEqual(t, oldId, newId)
+
+	// 旧的追踪连接全部关闭，新建的连接都转发到新的 id
+	hasOld := func() bool {
+		for _, redirect := range tracking.liveRedirects() {
+			if redirect == oldId {
+				return true
+			}
+		}
+		return false
+	}
+	for hasOld() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	holdConns()
+	for _, redirect := range tracking.liveRedirects() {
+		go_test_.Equal(t, newId, redirect)
+	}
+	go_test_.Equal(t, true, len(tracking.liveRedirects()) >= 3)
+}
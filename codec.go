@@ -0,0 +1,283 @@
+package go_redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// 值编解码器。Id 会写入每个值的头字节，读取时按头字节选择编解码器，所以切换编解码器后旧值依然能读出来。
+// 自定义编解码器需要通过 RegisterCodec 注册，Id 取值 1-15，1-4 已被内置编解码器占用
+type Codec interface {
+	Id() byte
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type Compression byte
+
+const (
+	CompressionNone Compression = iota
+	CompressionGzip
+	CompressionZstd
+)
+
+type CodecConfig struct {
+	Codec             Codec       // 默认 JSONCodec
+	Compression       Compression // 编码后的值超过 CompressThreshold 字节才压缩
+	CompressThreshold int         // 默认 1024
+}
+
+var (
+	JSONCodec     Codec = jsonCodec{}
+	MsgpackCodec  Codec = msgpackCodec{}
+	GobCodec      Codec = gobCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[byte]Codec{}
+)
+
+func init() {
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec, GobCodec, ProtobufCodec} {
+		if err := RegisterCodec(codec); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// 注册编解码器，读取时才能根据头字节找到它
+func RegisterCodec(codec Codec) error {
+	id := codec.Id()
+	if id == 0 || id > 0x0f {
+		return errors.Errorf("codec id <%d> out of range 1-15.", id)
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if exists, ok := codecs[id]; ok && exists != codec {
+		return errors.Errorf("codec id <%d> already registered by %T.", id, exists)
+	}
+	codecs[id] = codec
+	return nil
+}
+
+func codecById(id byte) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[id]
+	if !ok {
+		return nil, errors.Errorf("codec id <%d> not registered.", id)
+	}
+	return codec, nil
+}
+
+// 头字节：高 4 位是压缩方式，低 4 位是编解码器 id
+type valueCodec struct {
+	codec             Codec
+	compression       Compression
+	compressThreshold int
+}
+
+func newValueCodec(config *CodecConfig) (*valueCodec, error) {
+	c := &valueCodec{
+		codec:             JSONCodec,
+		compressThreshold: 1024,
+	}
+	if config == nil {
+		return c, nil
+	}
+	if config.Codec != nil {
+		if _, err := codecById(config.Codec.Id()); err != nil {
+			return nil, err
+		}
+		c.codec = config.Codec
+	}
+	switch config.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+		c.compression = config.Compression
+	default:
+		return nil, errors.Errorf("unsupported compression <%d>.", config.Compression)
+	}
+	if config.CompressThreshold > 0 {
+		c.compressThreshold = config.CompressThreshold
+	}
+	return c, nil
+}
+
+func (c *valueCodec) encode(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%T marshal failed.", c.codec)
+	}
+	compression := CompressionNone
+	if c.compression != CompressionNone && len(data) > c.compressThreshold {
+		data, err = compress(c.compression, data)
+		if err != nil {
+			return nil, err
+		}
+		compression = c.compression
+	}
+	result := make([]byte, 0, len(data)+1)
+	result = append(result, byte(compression)<<4|c.codec.Id())
+	return append(result, data...), nil
+}
+
+func (c *valueCodec) decode(data []byte, v any) error {
+	if len(data) == 0 {
+		return errors.New("empty value, missing codec header.")
+	}
+	codec, err := codecById(data[0] & 0x0f)
+	if err != nil {
+		return err
+	}
+	body, err := decompress(Compression(data[0]>>4), data[1:])
+	if err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(body, v); err != nil {
+		return errors.Wrapf(err, "%T unmarshal failed.", codec)
+	}
+	return nil
+}
+
+// 解压后的最大字节数，和 Redis 单个值的上限一致，防止恶意构造的值解压出超大数据
+var maxDecompressedSize = 512 << 20
+
+// 用到 zstd 时才创建，不压缩的使用方不用付出初始化的开销
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+		return zstd.NewWriter(nil)
+	})
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(maxDecompressedSize)))
+	})
+)
+
+func compress(compression Compression, data []byte) ([]byte, error) {
+	switch compression {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, errors.Wrap(err, "gzip compress failed.")
+		}
+		if err := w.Close(); err != nil {
+			return nil, errors.Wrap(err, "gzip compress failed.")
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		encoder, err := zstdEncoder()
+		if err != nil {
+			return nil, errors.Wrap(err, "zstd encoder init failed.")
+		}
+		return encoder.EncodeAll(data, nil), nil
+	}
+	return nil, errors.Errorf("unsupported compression <%d>.", compression)
+}
+
+func decompress(compression Compression, data []byte) ([]byte, error) {
+	switch compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrap(err, "gzip decompress failed.")
+		}
+		defer r.Close()
+		// 多读一个字节，用来判断是否超过上限
+		result, err := io.ReadAll(io.LimitReader(r, int64(maxDecompressedSize)+1))
+		if err != nil {
+			return nil, errors.Wrap(err, "gzip decompress failed.")
+		}
+		if len(result) > maxDecompressedSize {
+			return nil, errors.Errorf("gzip decompressed size exceeds <%d> bytes.", maxDecompressedSize)
+		}
+		return result, nil
+	case CompressionZstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, errors.Wrap(err, "zstd decoder init failed.")
+		}
+		result, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "zstd decompress failed.")
+		}
+		return result, nil
+	}
+	return nil, errors.Errorf("unsupported compression <%d>.", compression)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Id() byte { return 1 }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Id() byte { return 2 }
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) { return msgpack.Marshal(v) }
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Id() byte { return 3 }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// 只支持 proto.Message，读取时 T 需要是消息的指针类型，例如 GetValue[*pb.User]
+type protobufCodec struct{}
+
+func (protobufCodec) Id() byte { return 4 }
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("%T is not a proto.Message.", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	// v 是 **Message，先分配消息
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Pointer {
+		elem := rv.Elem()
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		if m, ok := elem.Interface().(proto.Message); ok {
+			return proto.Unmarshal(data, m)
+		}
+	}
+	return errors.Errorf("%T is not a pointer to proto.Message.", v)
+}
@@ -0,0 +1,168 @@
+package go_redis
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecTestUser struct {
+	Name string
+	Tags []string
+}
+
+func TestCodec_Migration(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	user := codecTestUser{Name: "pefish", Tags: []string{strings.Repeat("a", 100)}}
+
+	go_test_.Equal(t, nil, SetValue(instance.String, "test_codec_json", user, 0))
+
+	for _, codec := range []Codec{MsgpackCodec, GobCodec} {
+		for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+			go_test_.Equal(t, nil, instance.SetCodec(&CodecConfig{
+				Codec:             codec,
+				Compression:       compression,
+				CompressThreshold: 10,
+			}))
+			go_test_.Equal(t, nil, SetValue(instance.String, "test_codec", user, 0))
+			result, err := GetValue[codecTestUser](instance.String, "test_codec")
+			go_test_.Equal(t, nil, err)
+			go_test_.Equal(t, user, result)
+
+			// 旧的 JSON 值依然可以读出来
+			result, err = GetValue[codecTestUser](instance.String, "test_codec_json")
+			go_test_.Equal(t, nil, err)
+			go_test_.Equal(t, user, result)
+		}
+	}
+}
+
+func TestCodec_Protobuf(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	go_test_.Equal(t, nil, instance.SetCodec(&CodecConfig{
+		Codec: ProtobufCodec,
+	}))
+
+	_, err := RPushValue(instance.List, "test_codec_pb", wrapperspb.String("a"), wrapperspb.String("b"))
+	go_test_.Equal(t, nil, err)
+	results, err := RangeValue[*wrapperspb.StringValue](instance.List, "test_codec_pb", 0, -1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 2, len(results))
+	go_test_.Equal(t, "b", results[1].GetValue())
+
+	_, err = RPushValue(instance.List, "test_codec_pb", "not proto")
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestCodec_SetMembers(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	go_test_.Equal(t, nil, SAddValue(instance.Set, "test_codec_set", 1, 2, 2))
+	isMember, err := SIsMemberValue(instance.Set, "test_codec_set", 2)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, isMember)
+	members, err := SMembersValue[int](instance.Set, "test_codec_set")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 2, len(members))
+
+	go_test_.Equal(t, nil, ZAddValue(instance.OrderSet, "test_codec_zset", codecTestUser{Name: "a"}, 2))
+	go_test_.Equal(t, nil, ZAddValue(instance.OrderSet, "test_codec_zset", codecTestUser{Name: "b"}, 1))
+	users, err := ZRangeValue[codecTestUser](instance.OrderSet, "test_codec_zset", 0, -1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "b", users[0].Name)
+}
+
+func TestCodec_DecompressLimit(t *testing.T) {
+	data, err := compress(CompressionGzip, []byte(strings.Repeat("a", 100)))
+	go_test_.Equal(t, nil, err)
+
+	old := maxDecompressedSize
+	maxDecompressedSize = 10
+	t.Cleanup(func() { maxDecompressedSize = old })
+	_, err = decompress(CompressionGzip, data)
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "exceeds <10> bytes"))
+}
+
+func TestCodec_SetCodecConcurrent(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	view := instance.WithPrefix("app:")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			go_test_.Equal(t, nil, instance.SetCodec(&CodecConfig{Codec: MsgpackCodec}))
+			go_test_.Equal(t, nil, instance.SetCodec(nil))
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		go_test_.Equal(t, nil, SetValue(view.String, "test_codec_concurrent", i, 0))
+		result, err := GetValue[int](view.String, "test_codec_concurrent")
+		go_test_.Equal(t, nil, err)
+		go_test_.Equal(t, i, result)
+	}
+	wg.Wait()
+}
+
+// 解码后把输入清零，模拟原地解码的编解码器
+type inPlaceCodec struct{}
+
+func (inPlaceCodec) Id() byte { return 9 }
+
+func (inPlaceCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (inPlaceCodec) Unmarshal(data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	clear(data)
+	return err
+}
+
+func TestCodec_InputNotShared(t *testing.T) {
+	go_test_.Equal(t, nil, RegisterCodec(inPlaceCodec{}))
+	m := miniredis.RunT(t)
+	registerFakeTracking(m)
+	instance := New(&i_logger.DefaultLogger, 5*time.Second)
+	err := instance.Connect(&Configuration{
+		Url:         m.Addr(),
+		ClientCache: &ClientCacheConfig{},
+	})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+	go_test_.Equal(t, nil, instance.SetCodec(&CodecConfig{Codec: inPlaceCodec{}}))
+
+	go_test_.Equal(t, nil, SetValue(instance.String, "test_codec_in_place", "abc", 0))
+	for i := 0; i < 2; i++ {
+		// 第二次读命中本地缓存
+		result, err := GetValue[string](instance.String, "test_codec_in_place")
+		go_test_.Equal(t, nil, err)
+		go_test_.Equal(t, "abc", result)
+	}
+	go_test_.Equal(t, uint64(1), instance.ClientCacheStats().Hits)
+}
+
+func TestCodec_RangeIndex(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	_, err := RPushValue(instance.List, "test_codec_range", 1, 2)
+	go_test_.Equal(t, nil, err)
+	_, err = instance.List.RPush("test_codec_range", "x")
+	go_test_.Equal(t, nil, err)
+	_, err = instance.List.RPush("test_range_as_index", "1", "2", "x")
+	go_test_.Equal(t, nil, err)
+	// 两种读取方式报告的都是列表中的下标
+	_, err = RangeValue[int](instance.List, "test_codec_range", -2, -1)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_codec_range> <index: 2>"))
+	_, err = RangeAs[int](instance.List, "test_range_as_index", -2, -1)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_range_as_index> <index: 2>"))
+	_, err = RangeAs[int](instance.List, "test_range_as_index", -10, -1)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_range_as_index> <index: 2>"))
+}
@@ -0,0 +1,271 @@
+package go_redis
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 下面是通过 RedisType 上配置的 Codec 读写任意类型值的泛型函数（配置见 RedisType.SetCodec）。
+// key（field、元素）不存在时返回零值，需要区分的话用对应的 Ok 函数
+
+func encodeValue[T any](codec *valueCodec, value T) (string, error) {
+	data, err := codec.encode(value)
+	if err != nil {
+		return ``, err
+	}
+	return BytesToString(data), nil
+}
+
+func encodeValues[T any](codec *valueCodec, values []T) ([]string, error) {
+	results := make([]string, 0, len(values))
+	for i, value := range values {
+		str, err := encodeValue(codec, value)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "<index: %d>", i)
+		}
+		results = append(results, str)
+	}
+	return results, nil
+}
+
+func decodeValue[T any](codec *valueCodec, str string) (T, error) {
+	var result T
+	// 拷贝一份，自定义 Codec 可能会原地修改输入，不能交给它 string 的内存
+	if err := codec.decode([]byte(str), &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// firstIndex 返回 strs[0] 在列表（有序集合）中的下标，用于错误信息，只在出错时调用。为 nil 时下标从 0 开始
+func decodeValues[T any](codec *valueCodec, key string, strs []string, firstIndex func() int64) ([]T, error) {
+	if len(strs) == 0 {
+		return nil, nil
+	}
+	results := make([]T, 0, len(strs))
+	for i, str := range strs {
+		result, err := decodeValue[T](codec, str)
+		if err != nil {
+			index := int64(i)
+			if firstIndex != nil {
+				index += firstIndex()
+			}
+			return nil, errors.WithMessagef(err, "<key: %s> <index: %d>", key, index)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// 编码后设置指定 key 的值
+func SetValue[T any](t *StringType, key string, value T, expiration time.Duration) error {
+	str, err := encodeValue(t.codec.Load(), value)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.Set(key, str, expiration)
+}
+
+// 获取指定 key 的值并解码
+func GetValue[T any](t *StringType, key string) (T, error) {
+	result, _, err := GetValueOk[T](t, key)
+	return result, err
+}
+
+func GetValueOk[T any](t *StringType, key string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.GetOk(key)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := decodeValue[T](t.codec.Load(), str)
+	if err != nil {
+		return zero, true, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return result, true, nil
+}
+
+// 编码后将哈希表 key 中的字段 field 的值设为 value
+func HSetValue[T any](t *HashType, key, field string, value T) error {
+	str, err := encodeValue(t.codec.Load(), value)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s, field: %s>", key, field)
+	}
+	return t.Set(key, field, str)
+}
+
+// 获取存储在哈希表中指定字段的值并解码
+func HGetValue[T any](t *HashType, key, field string) (T, error) {
+	result, _, err := HGetValueOk[T](t, key, field)
+	return result, err
+}
+
+func HGetValueOk[T any](t *HashType, key, field string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.GetOk(key, field)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := decodeValue[T](t.codec.Load(), str)
+	if err != nil {
+		return zero, true, errors.WithMessagef(err, "<key: %s, field: %s>", key, field)
+	}
+	return result, true, nil
+}
+
+// 编码后将一个或多个值插入到列表头部
+func LPushValue[T any](t *ListType, key string, values ...T) (listLength_ uint64, err_ error) {
+	strs, err := encodeValues(t.codec.Load(), values)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.LPush(key, strs...)
+}
+
+// 编码后在列表中添加一个或多个值到列表尾部
+func RPushValue[T any](t *ListType, key string, values ...T) (listLength_ uint64, err_ error) {
+	strs, err := encodeValues(t.codec.Load(), values)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.RPush(key, strs...)
+}
+
+// 移出并获取列表的第一个元素
+func LPopValue[T any](t *ListType, key string) (T, error) {
+	result, _, err := LPopValueOk[T](t, key)
+	return result, err
+}
+
+func LPopValueOk[T any](t *ListType, key string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.LPopOk(key)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := decodeValue[T](t.codec.Load(), str)
+	if err != nil {
+		return zero, true, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return result, true, nil
+}
+
+// 移除列表的最后一个元素，返回值为移除的元素
+func RPopValue[T any](t *ListType, key string) (T, error) {
+	result, _, err := RPopValueOk[T](t, key)
+	return result, err
+}
+
+func RPopValueOk[T any](t *ListType, key string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.RPopOk(key)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := decodeValue[T](t.codec.Load(), str)
+	if err != nil {
+		return zero, true, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return result, true, nil
+}
+
+// 获取列表指定范围内的元素，key 不存在返回 nil,nil
+func RangeValue[T any](t *ListType, key string, start int64, stop int64) ([]T, error) {
+	strs, err := t.Range(key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[T](t.codec.Load(), key, strs, func() int64 {
+		return t.rangeStartIndex(key, start)
+	})
+}
+
+// 编码后向集合添加成员。同一个值每次编码的结果必须一致，否则无法去重
+func SAddValue[T any](t *SetType, key string, members ...T) error {
+	strs, err := encodeValues(t.codec.Load(), members)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	rawMembers := make([]any, 0, len(strs))
+	for _, str := range strs {
+		rawMembers = append(rawMembers, str)
+	}
+	return t.AddBatch(key, rawMembers)
+}
+
+// 移除集合中一个或多个成员
+func SRemValue[T any](t *SetType, key string, members ...T) error {
+	strs, err := encodeValues(t.codec.Load(), members)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.Remove(key, strs...)
+}
+
+// 判断 member 是否是集合 key 的成员
+func SIsMemberValue[T any](t *SetType, key string, member T) (bool, error) {
+	str, err := encodeValue(t.codec.Load(), member)
+	if err != nil {
+		return false, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.IsMember(key, str)
+}
+
+// 返回集合中的所有成员，key 不存在时返回 nil,nil
+func SMembersValue[T any](t *SetType, key string) ([]T, error) {
+	strs, err := t.Members(key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[T](t.codec.Load(), key, strs, nil)
+}
+
+// 编码后向有序集合添加成员，或者更新已存在成员的分数
+func ZAddValue[T any](t *OrderSetType, key string, member T, score float64) error {
+	str, err := encodeValue(t.codec.Load(), member)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.Add(key, str, score)
+}
+
+// 移除有序集合中的一个成员
+func ZRemValue[T any](t *OrderSetType, key string, member T) (bool, error) {
+	str, err := encodeValue(t.codec.Load(), member)
+	if err != nil {
+		return false, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.Remove(key, str)
+}
+
+// 返回有序集中成员的分数值，found 为 false 表示 key 或成员不存在
+func ZScoreValueOk[T any](t *OrderSetType, key string, member T) (result_ float64, found_ bool, err_ error) {
+	str, err := encodeValue(t.codec.Load(), member)
+	if err != nil {
+		return 0, false, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.ScoreOk(key, str)
+}
+
+// 返回有序集中指定索引区间内的成员，按分数值从小到大排序
+func ZRangeValue[T any](t *OrderSetType, key string, start int64, stop int64) ([]T, error) {
+	strs, err := t.Range(key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[T](t.codec.Load(), key, strs, func() int64 {
+		return t.rangeStartIndex(key, start)
+	})
+}
+
+// 返回有序集中指定索引区间内的成员，按分数值从大到小排序
+func ZRevRangeValue[T any](t *OrderSetType, key string, start int64, stop int64) ([]T, error) {
+	strs, err := t.RevRange(key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValues[T](t.codec.Load(), key, strs, func() int64 {
+		return t.rangeStartIndex(key, start)
+	})
+}
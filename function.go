@@ -0,0 +1,246 @@
+package go_redis
+
+import (
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 7 Functions 的库。版本号写在代码第二行的注释里（-- version: 3），LoadFunctionLibrary 根据它判断是否需要替换
+type FunctionLibrary struct {
+	Name    string
+	Version int
+	Code    string // 完整代码，以 #!lua name=<Name> 开头
+}
+
+type FunctionLibraryInfo struct {
+	Name      string
+	Version   int // 代码里没有版本注释时为 0
+	Functions []redis.Function
+	Code      string
+}
+
+var (
+	functionShebangRegexp = regexp.MustCompile(`^#!lua\s+name=(\S+)`)
+	functionVersionRegexp = regexp.MustCompile(`(?m)^--\s*version:\s*(\d+)\s*$`)
+)
+
+// 用 Go 代码里的 Lua 函数体创建库，会自动加上 #!lua 头和版本注释
+//
+//	var library = go_redis.NewFunctionLibrary("mylib", 2, `
+//	redis.register_function('incr_if_exists', function(keys, args)
+//		if redis.call('exists', keys[1]) == 1 then return redis.call('incr', keys[1]) end
+//		return 0
+//	end)`)
+func NewFunctionLibrary(name string, version int, body string) *FunctionLibrary {
+	return &FunctionLibrary{
+		Name:    name,
+		Version: version,
+		Code:    "#!lua name=" + name + "\n-- version: " + strconv.Itoa(version) + "\n" + strings.TrimLeft(body, "\n"),
+	}
+}
+
+// 解析完整的库代码，库名来自 #!lua 头，版本来自 -- version: N 注释（没有时为 0）
+func ParseFunctionLibrary(code string) (*FunctionLibrary, error) {
+	matches := functionShebangRegexp.FindStringSubmatch(code)
+	if matches == nil {
+		return nil, errors.New("missing #!lua name=<library> header.")
+	}
+	return &FunctionLibrary{
+		Name:    matches[1],
+		Version: functionCodeVersion(code),
+		Code:    code,
+	}, nil
+}
+
+// 从 embed.FS 等文件系统读取库代码
+//
+//	//go:embed lua/mylib.lua
+//	var luaFS embed.FS
+//	library, err := go_redis.ReadFunctionLibrary(luaFS, "lua/mylib.lua")
+func ReadFunctionLibrary(fsys fs.FS, path string) (*FunctionLibrary, error) {
+	code, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "<path: %s>", path)
+	}
+	library, err := ParseFunctionLibrary(string(code))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "<path: %s>", path)
+	}
+	return library, nil
+}
+
+func functionCodeVersion(code string) int {
+	matches := functionVersionRegexp.FindStringSubmatch(code)
+	if matches == nil {
+		return 0
+	}
+	version, _ := strconv.Atoi(matches[1])
+	return version
+}
+
+// 加载库。服务端已有同名库且版本不低于 library.Version 时不做任何事，返回 false；
+// 否则加载（已有低版本时替换），返回 true。多个进程同时加载不同版本时，低版本不会覆盖高版本
+func (t *RedisType) LoadFunctionLibrary(library *FunctionLibrary) (loaded_ bool, err_ error) {
+	// 先不带 REPLACE 加载，库不存在时一步完成，不会覆盖别人刚加载的库
+	loadErr := t.Db.FunctionLoad(t.ctx("function.load"), library.Code).Err()
+	if loadErr == nil {
+		t.logger.InfoF(`Redis function library loaded. library: %s, version: %d`, library.Name, library.Version)
+		return true, nil
+	}
+	// 不依赖错误信息的文本，查一下库是否已经存在
+	existing, found, err := t.getFunctionLibrary(library.Name)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, errors.Wrapf(loadErr, "<library: %s> <version: %d>", library.Name, library.Version)
+	}
+	if existing.Version >= library.Version {
+		return false, nil
+	}
+
+	// 比较版本和替换之间不能有别人替换，加锁后重新比较
+	unlock, err := t.lockFunctionLibrary(library.Name)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	existing, found, err = t.getFunctionLibrary(library.Name)
+	if err != nil {
+		return false, err
+	}
+	if found && existing.Version >= library.Version {
+		return false, nil
+	}
+	if err := t.Db.FunctionLoadReplace(t.ctx("function.load"), library.Code).Err(); err != nil {
+		return false, errors.Wrapf(err, "<library: %s> <version: %d>", library.Name, library.Version)
+	}
+	t.logger.InfoF(`Redis function library loaded. library: %s, version: %d`, library.Name, library.Version)
+	return true, nil
+}
+
+const (
+	functionLockExpiration = 10 * time.Second
+	functionLockWait       = 10 * time.Second
+)
+
+// 替换库时用的锁。库是整个服务端共享的，锁的 key 不加前缀
+func (t *RedisType) lockFunctionLibrary(name string) (unlock_ func(), err_ error) {
+	key := "go-redis:function:" + name + ":lock"
+	token := uuid.NewString()
+	deadline := time.Now().Add(functionLockWait)
+	for {
+		acquired, err := t.Db.SetNX(t.ctx("function.lock"), key, token, functionLockExpiration).Result()
+		if err != nil {
+			return nil, errors.Wrapf(err, "<key: %s>", key)
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("<key: %s> wait for function library lock timeout.", key)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return func() {
+		if err := releaseLockScript.script.Run(t.ctx("function.unlock"), t.Db, []string{key}, token).Err(); err != nil {
+			t.logger.WarnF(`Redis function library unlock failed. key: %s, err: %s`, key, err)
+		}
+	}, nil
+}
+
+func (t *RedisType) getFunctionLibrary(name string) (*FunctionLibraryInfo, bool, error) {
+	libraries, err := t.ListFunctionLibraries(name)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, library := range libraries {
+		if library.Name == name {
+			return &library, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// 列出库，pattern 为空时列出全部，支持 * 等通配符
+func (t *RedisType) ListFunctionLibraries(pattern string) ([]FunctionLibraryInfo, error) {
+	libraries, err := t.Db.FunctionList(t.ctx("function.list"), redis.FunctionListQuery{
+		LibraryNamePattern: pattern,
+		WithCode:           true,
+	}).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "<pattern: %s>", pattern)
+	}
+	results := make([]FunctionLibraryInfo, 0, len(libraries))
+	for _, library := range libraries {
+		results = append(results, FunctionLibraryInfo{
+			Name:      library.Name,
+			Version:   functionCodeVersion(library.Code),
+			Functions: library.Functions,
+			Code:      library.Code,
+		})
+	}
+	return results, nil
+}
+
+// 删除库，返回库是否存在
+func (t *RedisType) DeleteFunctionLibrary(name string) (bool, error) {
+	if err := t.Db.FunctionDelete(t.ctx("function.delete"), name).Err(); err != nil {
+		// 不依赖错误信息的文本，库不存在（包括被别人同时删掉）时不算错误
+		if _, found, listErr := t.getFunctionLibrary(name); listErr == nil && !found {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "<library: %s>", name)
+	}
+	return true, nil
+}
+
+// 调用函数（FCALL），key 会加上前缀，结果按 Script 的默认规则转换成 T。函数返回 nil 时返回零值
+func FCall[T any](t *RedisType, function string, keys []string, args ...any) (T, error) {
+	result, _, err := fcall[T](t, "function.fcall", function, keys, args)
+	return result, err
+}
+
+// 同 FCall，函数返回 nil 时 found_ 为 false
+func FCallOk[T any](t *RedisType, function string, keys []string, args ...any) (result_ T, found_ bool, err_ error) {
+	return fcall[T](t, "function.fcall", function, keys, args)
+}
+
+// 调用只读函数（FCALL_RO），配置了副本时会发到副本
+func FCallRO[T any](t *RedisType, function string, keys []string, args ...any) (T, error) {
+	result, _, err := fcall[T](t, "function.fcall_ro", function, keys, args)
+	return result, err
+}
+
+func fcall[T any](t *RedisType, operation string, function string, keys []string, args []any) (result_ T, found_ bool, err_ error) {
+	var zero T
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, t.prefix.key(key))
+	}
+	var cmd *redis.Cmd
+	if operation == "function.fcall_ro" {
+		cmd = t.Db.FCallRO(t.ctx(operation), function, prefixed, args...)
+	} else {
+		cmd = t.Db.FCall(t.ctx(operation), function, prefixed, args...)
+	}
+	result, err := cmd.Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return zero, false, nil
+		}
+		return zero, false, errors.Wrapf(err, "<function: %s> <keys: %v>", function, prefixed)
+	}
+	value, err := decodeScriptResult[T](result)
+	if err != nil {
+		return zero, false, errors.WithMessagef(err, "<function: %s> decode result failed", function)
+	}
+	return value, true, nil
+}
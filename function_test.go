@@ -0,0 +1,226 @@
+package go_redis
+
+import (
+	"context"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	go_test_ "github.com/pefish/go-test"
+)
+
+var fakeFunctionNameRegexp = regexp.MustCompile(`register_function\s*[({]\s*(?:function_name\s*=\s*)?'([^']+)'`)
+
+// miniredis 不支持 Functions，这里注册一个最小实现：库只记录代码和函数名，
+// 所有函数都返回 {第一个 key, 第一个参数}
+func registerFakeFunctions(t *testing.T, m *miniredis.Miniredis) {
+	var mu sync.Mutex
+	libraries := make(map[string]string)
+	functions := make(map[string]string)
+	srv := m.Server()
+	go_test_.Equal(t, nil, srv.Register("FUNCTION", func(c *server.Peer, cmd string, args []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch strings.ToUpper(args[0]) {
+		case "LOAD":
+			code := args[len(args)-1]
+			replace := len(args) == 3 && strings.EqualFold(args[1], "REPLACE")
+			library, err := ParseFunctionLibrary(code)
+			if err != nil {
+				c.WriteError("ERR " + err.Error())
+				return
+			}
+			if _, ok := libraries[library.Name]; ok && !replace {
+				c.WriteError("ERR Library '" + library.Name + "' already exists")
+				return
+			}
+			libraries[library.Name] = code
+			for _, matches := range fakeFunctionNameRegexp.FindAllStringSubmatch(code, -1) {
+				functions[matches[1]] = library.Name
+			}
+			c.WriteBulk(library.Name)
+		case "LIST":
+			pattern := "*"
+			if len(args) >= 3 && strings.EqualFold(args[1], "LIBRARYNAME") {
+				pattern = args[2]
+			}
+			names := make([]string, 0)
+			for name := range libraries {
+				if ok, _ := path.Match(pattern, name); ok {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			c.WriteLen(len(names))
+			for _, name := range names {
+				c.WriteMapLen(4)
+				c.WriteBulk("library_name")
+				c.WriteBulk(name)
+				c.WriteBulk("engine")
+				c.WriteBulk("LUA")
+				c.WriteBulk("functions")
+				fns := make([]string, 0)
+				for fn, library := range functions {
+					if library == name {
+						fns = append(fns, fn)
+					}
+				}
+				sort.Strings(fns)
+				c.WriteLen(len(fns))
+				for _, fn := range fns {
+					c.WriteMapLen(3)
+					c.WriteBulk("name")
+					c.WriteBulk(fn)
+					c.WriteBulk("description")
+					c.WriteNull()
+					c.WriteBulk("flags")
+					c.WriteLen(0)
+				}
+				c.WriteBulk("library_code")
+				c.WriteBulk(libraries[name])
+			}
+		case "DELETE":
+			if _, ok := libraries[args[1]]; !ok {
+				// 和真实服务端的文本不同，确认不依赖错误信息判断
+				c.WriteError("ERR no such library")
+				return
+			}
+			delete(libraries, args[1])
+			for fn, library := range functions {
+				if library == args[1] {
+					delete(functions, fn)
+				}
+			}
+			c.WriteOK()
+		default:
+			c.WriteError("ERR unknown subcommand")
+		}
+	}))
+	fcall := func(c *server.Peer, cmd string, args []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := functions[args[0]]; !ok {
+			c.WriteError("ERR Function not found")
+			return
+		}
+		numKeys, _ := strconv.Atoi(args[1])
+		rest := args[2:]
+		if numKeys == 0 || len(rest) <= numKeys {
+			c.WriteNull()
+			return
+		}
+		c.WriteStrings([]string{rest[0], rest[numKeys]})
+	}
+	go_test_.Equal(t, nil, srv.Register("FCALL", fcall))
+	go_test_.Equal(t, nil, srv.Register("FCALL_RO", fcall))
+}
+
+func TestFunction_Library(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	registerFakeFunctions(t, m)
+
+	library, err := ReadFunctionLibrary(os.DirFS("testdata"), "queue.lua")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "queue", library.Name)
+	go_test_.Equal(t, 2, library.Version)
+
+	loaded, err := instance.LoadFunctionLibrary(library)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, loaded)
+	// 同版本不重复加载
+	loaded, err = instance.LoadFunctionLibrary(library)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, loaded)
+	loaded, err = instance.LoadFunctionLibrary(NewFunctionLibrary("queue", 1, `redis.register_function('old', function() end)`))
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, loaded)
+
+	libraries, err := instance.ListFunctionLibraries("")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 1, len(libraries))
+	go_test_.Equal(t, 2, libraries[0].Version)
+	go_test_.Equal(t, 2, len(libraries[0].Functions))
+
+	// 高版本替换
+	upgrade := NewFunctionLibrary("queue", 3, `
+redis.register_function('echo', function(keys, args) return {keys[1], args[1]} end)`)
+	go_test_.Equal(t, true, strings.HasPrefix(upgrade.Code, "#!lua name=queue\n-- version: 3\nredis"))
+	loaded, err = instance.LoadFunctionLibrary(upgrade)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, loaded)
+	libraries, err = instance.ListFunctionLibraries("q*")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, libraries[0].Version)
+
+	deleted, err := instance.DeleteFunctionLibrary("queue")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, deleted)
+	deleted, err = instance.DeleteFunctionLibrary("queue")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, deleted)
+
+	_, err = ParseFunctionLibrary("redis.register_function('x', function() end)")
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestFunction_FCall(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	registerFakeFunctions(t, m)
+	_, err := instance.LoadFunctionLibrary(NewFunctionLibrary("lib", 1, `redis.register_function('echo', function(keys, args) return {keys[1], args[1]} end)`))
+	go_test_.Equal(t, nil, err)
+
+	results, err := FCall[[]string](instance.WithPrefix("p:"), "echo", []string{"k"}, 1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"p:k", "1"}, results)
+
+	numbers, err := FCallRO[[]uint64](instance, "echo", []string{"7"}, 8)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []uint64{7, 8}, numbers)
+
+	_, found, err := FCallOk[string](instance, "echo", nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+
+	_, err = FCall[string](instance, "none", nil)
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestFunction_LoadRace(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	registerFakeFunctions(t, m)
+	_, err := instance.LoadFunctionLibrary(NewFunctionLibrary("lib", 1, `redis.register_function('f1', function() end)`))
+	go_test_.Equal(t, nil, err)
+
+	// 另一个进程拿着锁，在它替换成 v3 期间开始加载 v2
+	lockKey := "go-redis:function:lib:lock"
+	go_test_.Equal(t, nil, instance.Db.Set(context.Background(), lockKey, "other", time.Minute).Err())
+	result := make(chan bool, 1)
+	go func() {
+		loaded, err := instance.LoadFunctionLibrary(NewFunctionLibrary("lib", 2, `redis.register_function('f2', function() end)`))
+		go_test_.Equal(t, nil, err)
+		result <- loaded
+	}()
+	time.Sleep(100 * time.Millisecond)
+	v3 := NewFunctionLibrary("lib", 3, `redis.register_function('f3', function() end)`)
+	go_test_.Equal(t, nil, instance.Db.FunctionLoadReplace(context.Background(), v3.Code).Err())
+	m.Del(lockKey)
+
+	// 拿到锁后重新比较版本，v2 不会覆盖 v3
+	go_test_.Equal(t, false, <-result)
+	libraries, err := instance.ListFunctionLibraries("lib")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, libraries[0].Version)
+	go_test_.Equal(t, false, m.Exists(lockKey))
+
+	// 库不存在时加载失败，返回加载的错误
+	_, err = instance.LoadFunctionLibrary(&FunctionLibrary{Name: "broken", Version: 1, Code: "not lua"})
+	go_test_.NotEqual(t, nil, err)
+}
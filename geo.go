@@ -0,0 +1,305 @@
+package go_redis
+
+import (
+	"context"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// 地理位置，底层是有序集合，score 是 geohash，所以同一个 key 也可以用 OrderSet 的方法删除、统计成员
+type GeoType struct {
+	db      *redis.Client
+	logger  i_logger.ILogger
+	prefix  keyPrefix
+	baseCtx context.Context
+}
+
+type GeoUnit string
+
+const (
+	GeoMeters     GeoUnit = "m"
+	GeoKilometers GeoUnit = "km"
+	GeoMiles      GeoUnit = "mi"
+	GeoFeet       GeoUnit = "ft"
+)
+
+func (u GeoUnit) validate() error {
+	switch u {
+	case GeoMeters, GeoKilometers, GeoMiles, GeoFeet:
+		return nil
+	}
+	return errors.Errorf("<unit: %s> invalid.", u)
+}
+
+type GeoLocation struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+type GeoPosition struct {
+	Longitude float64
+	Latitude  float64
+}
+
+type GeoAddOptions struct {
+	NX bool // 只添加新成员，不更新已有成员
+	XX bool // 只更新已有成员，不添加新成员
+	CH bool // 返回值包括位置被修改的成员，不只是新添加的成员
+}
+
+// 添加或更新成员的位置，options 可以为 nil。返回新添加的成员数，CH 时返回新添加和被修改的成员数
+func (t *GeoType) Add(key string, options *GeoAddOptions, locations ...GeoLocation) (int64, error) {
+	key = t.prefix.key(key)
+	if len(locations) == 0 {
+		return 0, errors.Errorf("<key: %s> locations is empty.", key)
+	}
+	args := []any{"geoadd", key}
+	if options != nil {
+		if options.NX && options.XX {
+			return 0, errors.Errorf("<key: %s> NX and XX are mutually exclusive.", key)
+		}
+		if options.NX {
+			args = append(args, "nx")
+		}
+		if options.XX {
+			args = append(args, "xx")
+		}
+		if options.CH {
+			args = append(args, "ch")
+		}
+	}
+	for _, location := range locations {
+		args = append(args, location.Longitude, location.Latitude, location.Member)
+	}
+	result, err := t.db.Do(t.ctx("add"), args...).Int64()
+	if err != nil {
+		return 0, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return result, nil
+}
+
+// 返回成员的位置，和 members 一一对应，成员不存在时为 nil
+func (t *GeoType) Pos(key string, members ...string) ([]*GeoPosition, error) {
+	key = t.prefix.key(key)
+	result, err := t.db.GeoPos(t.ctx("pos"), key, members...).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "<key: %s>", key)
+	}
+	positions := make([]*GeoPosition, 0, len(result))
+	for _, pos := range result {
+		if pos == nil {
+			positions = append(positions, nil)
+			continue
+		}
+		positions = append(positions, &GeoPosition{
+			Longitude: pos.Longitude,
+			Latitude:  pos.Latitude,
+		})
+	}
+	return positions, nil
+}
+
+// 返回两个成员之间的距离，任一成员不存在时 found_ 为 false
+func (t *GeoType) Dist(key string, member1 string, member2 string, unit GeoUnit) (dist_ float64, found_ bool, err_ error) {
+	key = t.prefix.key(key)
+	if err := unit.validate(); err != nil {
+		return 0, false, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	result, err := t.db.GeoDist(t.ctx("dist"), key, member1, member2, string(unit)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return result, true, nil
+}
+
+// 返回成员的 11 位 geohash 字符串，和 members 一一对应，成员不存在时为空字符串
+func (t *GeoType) Hash(key string, members ...string) ([]string, error) {
+	key = t.prefix.key(key)
+	result, err := t.db.GeoHash(t.ctx("hash"), key, members...).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return result, nil
+}
+
+type GeoSearchResult struct {
+	Member    string
+	Dist      float64 // WithDist 时有值，单位和搜索范围的单位相同
+	Longitude float64 // WithCoord 时有值
+	Latitude  float64 // WithCoord 时有值
+}
+
+// GEOSEARCH 命令构造器（Redis 6.2）。中心点用 FromMember 或 FromLonLat 指定，范围用 Radius 或 Box 指定
+//
+//	drivers, err := instance.Geo.Search("drivers").
+//		FromLonLat(116.397, 39.908).
+//		Radius(3, go_redis.GeoKilometers).
+//		Asc().
+//		Count(10, false).
+//		WithDist().
+//		Exec()
+type GeoSearchBuilder struct {
+	t         *GeoType
+	key       string
+	query     redis.GeoSearchLocationQuery
+	hasCenter bool
+	hasShape  bool
+	err       error
+}
+
+func (t *GeoType) Search(key string) *GeoSearchBuilder {
+	return &GeoSearchBuilder{
+		t:   t,
+		key: key,
+	}
+}
+
+func (b *GeoSearchBuilder) setErr(err error) *GeoSearchBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// 以已有成员的位置为中心
+func (b *GeoSearchBuilder) FromMember(member string) *GeoSearchBuilder {
+	if b.hasCenter {
+		return b.setErr(errors.New("center already set."))
+	}
+	if member == "" {
+		return b.setErr(errors.New("member is empty."))
+	}
+	b.hasCenter = true
+	b.query.Member = member
+	return b
+}
+
+// 以经纬度为中心
+func (b *GeoSearchBuilder) FromLonLat(longitude float64, latitude float64) *GeoSearchBuilder {
+	if b.hasCenter {
+		return b.setErr(errors.New("center already set."))
+	}
+	b.hasCenter = true
+	b.query.Longitude = longitude
+	b.query.Latitude = latitude
+	return b
+}
+
+// 圆形范围
+func (b *GeoSearchBuilder) Radius(radius float64, unit GeoUnit) *GeoSearchBuilder {
+	if b.hasShape {
+		return b.setErr(errors.New("shape already set."))
+	}
+	if err := unit.validate(); err != nil {
+		return b.setErr(err)
+	}
+	if radius <= 0 {
+		return b.setErr(errors.Errorf("<radius: %v> must be positive.", radius))
+	}
+	b.hasShape = true
+	b.query.Radius = radius
+	b.query.RadiusUnit = string(unit)
+	return b
+}
+
+// 以中心点为中心的矩形范围
+func (b *GeoSearchBuilder) Box(width float64, height float64, unit GeoUnit) *GeoSearchBuilder {
+	if b.hasShape {
+		return b.setErr(errors.New("shape already set."))
+	}
+	if err := unit.validate(); err != nil {
+		return b.setErr(err)
+	}
+	if width <= 0 || height <= 0 {
+		return b.setErr(errors.Errorf("<width: %v> <height: %v> must be positive.", width, height))
+	}
+	b.hasShape = true
+	b.query.BoxWidth = width
+	b.query.BoxHeight = height
+	b.query.BoxUnit = string(unit)
+	return b
+}
+
+// 按距离从近到远排序
+func (b *GeoSearchBuilder) Asc() *GeoSearchBuilder {
+	b.query.Sort = "ASC"
+	return b
+}
+
+// 按距离从远到近排序
+func (b *GeoSearchBuilder) Desc() *GeoSearchBuilder {
+	b.query.Sort = "DESC"
+	return b
+}
+
+// 最多返回 count 个结果。countAny 为 true 时（COUNT ANY）找到足够的结果就返回，不保证是最近的，但更快
+func (b *GeoSearchBuilder) Count(count int, countAny bool) *GeoSearchBuilder {
+	if count <= 0 {
+		return b.setErr(errors.Errorf("<count: %d> must be positive.", count))
+	}
+	b.query.Count = count
+	b.query.CountAny = countAny
+	return b
+}
+
+// 结果带上经纬度
+func (b *GeoSearchBuilder) WithCoord() *GeoSearchBuilder {
+	b.query.WithCoord = true
+	return b
+}
+
+// 结果带上到中心点的距离
+func (b *GeoSearchBuilder) WithDist() *GeoSearchBuilder {
+	b.query.WithDist = true
+	return b
+}
+
+func (b *GeoSearchBuilder) Exec() ([]GeoSearchResult, error) {
+	key := b.t.prefix.key(b.key)
+	if b.err != nil {
+		return nil, errors.WithMessagef(b.err, "<key: %s>", key)
+	}
+	if !b.hasCenter {
+		return nil, errors.Errorf("<key: %s> FromMember or FromLonLat is required.", key)
+	}
+	if !b.hasShape {
+		return nil, errors.Errorf("<key: %s> Radius or Box is required.", key)
+	}
+	query := b.query
+	if !query.WithCoord && !query.WithDist {
+		// 没有 WITH 选项时返回的是成员名数组，GeoSearchLocation 无法解析
+		members, err := b.t.db.GeoSearch(b.t.ctx("search"), key, &query.GeoSearchQuery).Result()
+		if err != nil {
+			return nil, errors.Wrapf(err, "<key: %s>", key)
+		}
+		results := make([]GeoSearchResult, 0, len(members))
+		for _, member := range members {
+			results = append(results, GeoSearchResult{Member: member})
+		}
+		return results, nil
+	}
+	locations, err := b.t.db.GeoSearchLocation(b.t.ctx("search"), key, &query).Result()
+	if err != nil {
+		return nil, errors.Wrapf(err, "<key: %s>", key)
+	}
+	results := make([]GeoSearchResult, 0, len(locations))
+	for _, location := range locations {
+		results = append(results, GeoSearchResult{
+			Member:    location.Name,
+			Dist:      location.Dist,
+			Longitude: location.Longitude,
+			Latitude:  location.Latitude,
+		})
+	}
+	return results, nil
+}
+
+func (t *GeoType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "geo."+operation)
+}
@@ -0,0 +1,190 @@
+package go_redis
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2/server"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	palermo = GeoLocation{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556}
+	catania = GeoLocation{Member: "Catania", Longitude: 15.087269, Latitude: 37.502669}
+)
+
+func TestGeoType_AddPosDist(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	added, err := instance.Geo.Add("sicily", nil, palermo, catania)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(2), added)
+
+	positions, err := instance.Geo.Pos("sicily", "Palermo", "missing")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 2, len(positions))
+	go_test_.Equal(t, true, math.Abs(positions[0].Longitude-palermo.Longitude) < 1e-5)
+	go_test_.Equal(t, true, math.Abs(positions[0].Latitude-palermo.Latitude) < 1e-5)
+	go_test_.Equal(t, (*GeoPosition)(nil), positions[1])
+
+	dist, found, err := instance.Geo.Dist("sicily", "Palermo", "Catania", GeoKilometers)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, found)
+	go_test_.Equal(t, true, math.Abs(dist-166.2742) < 0.01)
+	_, found, err = instance.Geo.Dist("sicily", "Palermo", "missing", GeoMeters)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	_, _, err = instance.Geo.Dist("sicily", "Palermo", "Catania", "yd")
+	go_test_.NotEqual(t, nil, err)
+
+	_, err = instance.Geo.Add("sicily", &GeoAddOptions{NX: true, XX: true}, palermo)
+	go_test_.NotEqual(t, nil, err)
+	_, err = instance.Geo.Add("sicily", nil)
+	go_test_.NotEqual(t, nil, err)
+}
+
+// miniredis 的 GEOADD 不支持 NX、XX、CH，在 go-redis 的钩子里拦截命令，检查参数并返回结果
+type geoAddInterceptor struct {
+	args []any
+}
+
+func (h *geoAddInterceptor) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *geoAddInterceptor) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() != "geoadd" {
+			return next(ctx, cmd)
+		}
+		h.args = cmd.Args()
+		cmd.(*redis.Cmd).SetVal(int64(1))
+		return nil
+	}
+}
+
+func (h *geoAddInterceptor) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestGeoType_AddOptions(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	interceptor := &geoAddInterceptor{}
+	instance.Db.AddHook(interceptor)
+
+	changed, err := instance.WithPrefix("geo:").Geo.Add("drivers", &GeoAddOptions{XX: true, CH: true}, palermo)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(1), changed)
+	go_test_.Equal(t, []any{"geoadd", "geo:drivers", "xx", "ch", palermo.Longitude, palermo.Latitude, "Palermo"}, interceptor.args)
+
+	_, err = instance.Geo.Add("drivers", &GeoAddOptions{NX: true}, catania)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []any{"geoadd", "drivers", "nx", catania.Longitude, catania.Latitude, "Catania"}, interceptor.args)
+}
+
+func TestGeoType_Hash(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	// miniredis 不支持 GEOHASH
+	go_test_.Equal(t, nil, m.Server().Register("GEOHASH", func(c *server.Peer, cmd string, args []string) {
+		c.WriteLen(len(args) - 1)
+		for _, member := range args[1:] {
+			if member == "Palermo" {
+				c.WriteBulk("sqc8b49rny0")
+			} else {
+				c.WriteNull()
+			}
+		}
+	}))
+
+	hashes, err := instance.Geo.Hash("sicily", "Palermo", "missing")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"sqc8b49rny0", ""}, hashes)
+}
+
+func TestGeoType_Search(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	var received []string
+	// miniredis 不支持 GEOSEARCH，这里按 WITHDIST、WITHCOORD 返回固定的两个结果，格式和 Redis 相同
+	go_test_.Equal(t, nil, m.Server().Register("GEOSEARCH", func(c *server.Peer, cmd string, args []string) {
+		received = args
+		var withDist, withCoord bool
+		for _, arg := range args {
+			withDist = withDist || strings.EqualFold(arg, "withdist")
+			withCoord = withCoord || strings.EqualFold(arg, "withcoord")
+		}
+		results := []struct {
+			member, dist, lon, lat string
+		}{
+			{"Palermo", "190.4424", "13.36138933897018433", "38.11555639549629859"},
+			{"Catania", "56.4413", "15.08726745843887329", "37.50266842333162032"},
+		}
+		c.WriteLen(len(results))
+		for _, result := range results {
+			if !withDist && !withCoord {
+				c.WriteBulk(result.member)
+				continue
+			}
+			n := 1
+			if withDist {
+				n++
+			}
+			if withCoord {
+				n++
+			}
+			c.WriteLen(n)
+			c.WriteBulk(result.member)
+			if withDist {
+				c.WriteBulk(result.dist)
+			}
+			if withCoord {
+				c.WriteLen(2)
+				c.WriteBulk(result.lon)
+				c.WriteBulk(result.lat)
+			}
+		}
+	}))
+
+	results, err := instance.WithPrefix("geo:").Geo.Search("sicily").
+		FromLonLat(15, 37).
+		Radius(200, GeoKilometers).
+		Desc().
+		Count(2, true).
+		WithCoord().
+		WithDist().
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "geo:sicily fromlonlat 15 37 byradius 200 km DESC count 2 any withcoord withdist", strings.Join(received, " "))
+	go_test_.Equal(t, 2, len(results))
+	go_test_.Equal(t, "Palermo", results[0].Member)
+	go_test_.Equal(t, 190.4424, results[0].Dist)
+	go_test_.Equal(t, true, math.Abs(results[0].Longitude-palermo.Longitude) < 1e-5)
+	go_test_.Equal(t, true, math.Abs(results[1].Latitude-catania.Latitude) < 1e-5)
+
+	results, err = instance.Geo.Search("sicily").
+		FromMember("Palermo").
+		Box(400, 400, GeoMiles).
+		Asc().
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "sicily frommember Palermo bybox 400 400 mi ASC", strings.Join(received, " "))
+	go_test_.Equal(t, []GeoSearchResult{{Member: "Palermo"}, {Member: "Catania"}}, results)
+
+	invalid := []*GeoSearchBuilder{
+		instance.Geo.Search("sicily").Radius(1, GeoMeters),
+		instance.Geo.Search("sicily").FromMember("Palermo"),
+		instance.Geo.Search("sicily").FromMember("Palermo").FromLonLat(1, 1).Radius(1, GeoMeters),
+		instance.Geo.Search("sicily").FromMember("Palermo").Radius(1, GeoMeters).Box(1, 1, GeoMeters),
+		instance.Geo.Search("sicily").FromMember("Palermo").Radius(0, GeoMeters),
+		instance.Geo.Search("sicily").FromMember("Palermo").Radius(1, "yd"),
+		instance.Geo.Search("sicily").FromMember("Palermo").Radius(1, GeoMeters).Count(0, false),
+	}
+	for i, builder := range invalid {
+		_, err := builder.Exec()
+		if err == nil {
+			t.Fatalf("builder %d: want error", i)
+		}
+	}
+}
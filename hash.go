@@ -3,6 +3,7 @@ package go_redis
 import (
 	"context"
 	"strconv"
+	"sync/atomic"
 
 	i_logger "github.com/pefish/go-interface/i-logger"
 	"github.com/pkg/errors"
@@ -10,72 +11,74 @@ import (
 )
 
 type HashType struct {
-	db     *redis.Client
-	logger i_logger.ILogger
+	db          *redis.Client
+	logger      i_logger.ILogger
+	clientCache *clientCache
+	codec       *atomic.Pointer[valueCodec]
+	prefix      keyPrefix
+	baseCtx     context.Context
 }
 
 func (t *HashType) Exists(key, field string) (bool, error) {
-	t.logger.DebugF(`Redis hexists. key: %s, field: %s`, key, field)
-	result, err := t.db.HExists(context.Background(), key, field).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HExists(t.ctx("exists"), key, field).Result()
 	if err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
-	t.logger.DebugF(`Redis hexists. result: %s`, result)
 	return result, nil
 }
 
-// 获取存储在哈希表中指定字段的值。不存在就返回空字符串
+// 获取存储在哈希表中指定字段的值。不存在就返回空字符串，需要区分的话用 GetOk
 func (t *HashType) Get(key, field string) (string, error) {
-	t.logger.DebugF(`Redis hget. key: %s, field: %s`, key, field)
-	result, err := t.db.HGet(context.Background(), key, field).Result()
+	result, _, err := t.GetOk(key, field)
+	return result, err
+}
+
+// 获取存储在哈希表中指定字段的值，found 为 false 表示 key 或 field 不存在
+func (t *HashType) GetOk(key, field string) (result_ string, found_ bool, err_ error) {
+	key = t.prefix.key(key)
+	result, err := t.db.HGet(t.ctx("get"), key, field).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
-			return ``, nil
+		if errors.Is(err, redis.Nil) {
+			return ``, false, nil
 		}
-		return ``, errors.Wrapf(err, "<key: %s, field: %s>", key, field)
+		return ``, false, errors.Wrapf(err, "<key: %s, field: %s>", key, field)
 	}
-	t.logger.DebugF(`Redis hget. result: %s`, result)
-	return result, nil
+	return result, true, nil
 }
 
 func (t *HashType) GetBatch(key string, fields []string) ([]any, error) {
-	t.logger.DebugF(`Redis hmget. key: %s, fields: ...`, key)
-	result, err := t.db.HMGet(context.Background(), key, fields...).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HMGet(t.ctx("getbatch"), key, fields...).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s, fields: ...>", key)
 	}
-	t.logger.DebugF(`Redis hmget. result: %s`, result)
 	return result, nil
 }
 
 func (t *HashType) RandomGetFields(key string, count int) ([]string, error) {
-	t.logger.DebugF(`Redis HRandField. key: %s, count: %d`, key, count)
-	result, err := t.db.HRandField(context.Background(), key, count).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HRandField(t.ctx("randomgetfields"), key, count).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
 	}
-	t.logger.DebugF(`Redis HRandField. result: %s`, result)
 	return result, nil
 }
 
 // 如果 key/field 不存在或者内容是空字符串，都返回 0
 func (t *HashType) GetUint64(key, field string) (uint64, error) {
-	t.logger.DebugF(`Redis hget. key: %s, field: %s`, key, field)
-	result, err := t.db.HGet(context.Background(), key, field).Uint64()
-	if err != nil {
-		if err.Error() == `redis: nil` {
-			return 0, nil
-		}
-		return 0, errors.Wrapf(err, "<key: %s, field: %s>", key, field)
-	}
-	t.logger.DebugF(`Redis hget. result: %s`, result)
-	return result, nil
+	result, _, err := t.GetUint64Ok(key, field)
+	return result, err
+}
+
+func (t *HashType) GetUint64Ok(key, field string) (result_ uint64, found_ bool, err_ error) {
+	return HGetAsOk[uint64](t, key, field)
 }
 
 func (t *HashType) GetFloat64(key, field string) (float64, error) {
@@ -94,24 +97,50 @@ func (t *HashType) GetFloat64(key, field string) (float64, error) {
 	return r, nil
 }
 
+func (t *HashType) GetFloat64Ok(key, field string) (result_ float64, found_ bool, err_ error) {
+	return HGetAsOk[float64](t, key, field)
+}
+
 // 获取在哈希表中指定 key 的所有字段和值
 func (t *HashType) GetAll(key string) (map[string]string, error) {
-	t.logger.DebugF(`Redis hgetall. key: %s`, key)
-	result, err := t.db.HGetAll(context.Background(), key).Result()
+	key = t.prefix.key(key)
+	if t.clientCache != nil {
+		return t.getAllCached(key)
+	}
+	result, err := t.db.HGetAll(t.ctx("getall"), key).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return map[string]string{}, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
 	}
-	t.logger.DebugF(`Redis hgetall. result: %s`, result)
+	return result, nil
+}
+
+func (t *HashType) getAllCached(key string) (map[string]string, error) {
+	cacheKey := clientCacheKey("hash", key)
+	value, seq, ok := t.clientCache.get(cacheKey)
+	if !ok {
+		result, err := t.clientCache.tracking().HGetAll(t.ctx("getall"), key).Result()
+		if err != nil {
+			return nil, errors.Wrapf(err, "<key: %s>", key)
+		}
+		t.clientCache.set(cacheKey, key, result, seq)
+		value = result
+	}
+	// 返回副本，防止调用方修改缓存内容
+	cached := value.(map[string]string)
+	result := make(map[string]string, len(cached))
+	for k, v := range cached {
+		result[k] = v
+	}
 	return result, nil
 }
 
 // 将哈希表 key 中的字段 field 的值设为 value 。
 func (t *HashType) Set(key, field, value string) error {
-	t.logger.DebugF(`Redis hset. key: %s, field: %s, value: %s`, key, field, value)
-	_, err := t.db.HSet(context.Background(), key, field, value).Result()
+	key = t.prefix.key(key)
+	_, err := t.db.HSet(t.ctx("set"), key, field, value).Result()
 	if err != nil {
 		return errors.Wrapf(err, "<key: %s, field: %s>", key, field)
 	}
@@ -119,8 +148,8 @@ func (t *HashType) Set(key, field, value string) error {
 }
 
 func (t *HashType) SetBatch(key string, fieldValues map[string]any) error {
-	t.logger.DebugF(`Redis hset. key: %s, fieldValues: ...`, key)
-	_, err := t.db.HSet(context.Background(), key, fieldValues).Result()
+	key = t.prefix.key(key)
+	_, err := t.db.HSet(t.ctx("setbatch"), key, fieldValues).Result()
 	if err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -128,17 +157,12 @@ func (t *HashType) SetBatch(key string, fieldValues map[string]any) error {
 }
 
 func (t *HashType) SetUint64(key, field string, value uint64) error {
-	t.logger.DebugF(`Redis hset. key: %s, field: %s, value: %s`, key, field, value)
-	_, err := t.db.HSet(context.Background(), key, field, value).Result()
-	if err != nil {
-		return errors.Wrapf(err, "<key: %s, field: %s>", key, field)
-	}
-	return nil
+	return HSetAs(t, key, field, value)
 }
 
 func (t *HashType) SetNX(key, field string, value string) (bool, error) {
-	t.logger.DebugF(`Redis hsetnx. key: %s, field: %s, value: %s`, key, field, value)
-	result, err := t.db.HSetNX(context.Background(), key, field, value).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HSetNX(t.ctx("setnx"), key, field, value).Result()
 	if err != nil {
 		return false, errors.Wrapf(err, "<key: %s, field: %s>", key, field)
 	}
@@ -146,8 +170,8 @@ func (t *HashType) SetNX(key, field string, value string) (bool, error) {
 }
 
 func (t *HashType) Del(key, field string) (bool, error) {
-	t.logger.DebugF(`Redis hdel. key: %s, field: %s`, key, field)
-	result := t.db.HDel(context.Background(), key, field)
+	key = t.prefix.key(key)
+	result := t.db.HDel(t.ctx("del"), key, field)
 	if result.Err() != nil {
 		return false, errors.Wrapf(result.Err(), "<key: %s, field: %s>", key, field)
 	}
@@ -156,8 +180,8 @@ func (t *HashType) Del(key, field string) (bool, error) {
 
 // 返回被成功删除的数量
 func (t *HashType) DelBatch(key string, fields []string) (int64, error) {
-	t.logger.DebugF(`Redis hdel. key: %s, fields length: %d`, key, len(fields))
-	result := t.db.HDel(context.Background(), key, fields...)
+	key = t.prefix.key(key)
+	result := t.db.HDel(t.ctx("delbatch"), key, fields...)
 	if result.Err() != nil {
 		return 0, errors.Wrapf(result.Err(), "<key: %s, fields length: %d>", key, len(fields))
 	}
@@ -165,8 +189,8 @@ func (t *HashType) DelBatch(key string, fields []string) (int64, error) {
 }
 
 func (t *HashType) Len(key string) (int64, error) {
-	t.logger.DebugF(`Redis hlen. key: %s`, key)
-	result, err := t.db.HLen(context.Background(), key).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HLen(t.ctx("len"), key).Result()
 	if err != nil {
 		return 0, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -174,8 +198,8 @@ func (t *HashType) Len(key string) (int64, error) {
 }
 
 func (t *HashType) Fields(key string) ([]string, error) {
-	t.logger.DebugF(`Redis keys. key: %s`, key)
-	result, err := t.db.HKeys(context.Background(), key).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HKeys(t.ctx("fields"), key).Result()
 	if err != nil {
 		return nil, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -183,8 +207,8 @@ func (t *HashType) Fields(key string) ([]string, error) {
 }
 
 func (t *HashType) Values(key string) ([]string, error) {
-	t.logger.DebugF(`Redis hvals. key: %s`, key)
-	result, err := t.db.HVals(context.Background(), key).Result()
+	key = t.prefix.key(key)
+	result, err := t.db.HVals(t.ctx("values"), key).Result()
 	if err != nil {
 		return nil, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -192,11 +216,15 @@ func (t *HashType) Values(key string) ([]string, error) {
 }
 
 func (t *HashType) IncrBy(key string, field string, increment int64) (int64, error) {
-	t.logger.DebugF(`Redis HIncrBy. key: %s, field: %s, increment: %f`, key, field, increment)
-	result := t.db.HIncrBy(context.Background(), key, field, increment)
+	key = t.prefix.key(key)
+	result := t.db.HIncrBy(t.ctx("incrby"), key, field, increment)
 	if err := result.Err(); err != nil {
 		return 0, errors.Wrapf(err, "<key: %s>", key)
 	}
 
 	return result.Val(), nil
 }
+
+func (t *HashType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "hash."+operation)
+}
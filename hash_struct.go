@@ -0,0 +1,213 @@
+package go_redis
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// 结构体和哈希表之间的映射，通过 `redis:"field"` 标签指定字段名：
+//
+//	type User struct {
+//		Name    string            `redis:"name"`
+//		Age     int               `redis:"age,omitempty"`
+//		Profile map[string]string `redis:"profile"` // 结构体、map、slice 以 JSON 存储
+//	}
+//
+// 没有标签或者标签为 "-" 的字段会被忽略，匿名嵌入的结构体如果没有标签会被展开
+
+type structField struct {
+	name      string // 哈希表中的字段名
+	index     []int
+	omitEmpty bool
+	json      bool
+}
+
+var structFieldsCache sync.Map // reflect.Type -> []structField
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+var float32VectorType = reflect.TypeOf(Float32Vector(nil))
+
+func cachedStructFields(typ reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(typ); ok {
+		return fields.([]structField)
+	}
+	fields := parseStructFields(typ, nil)
+	structFieldsCache.Store(typ, fields)
+	return fields
+}
+
+func parseStructFields(typ reflect.Type, parentIndex []int) []structField {
+	fields := make([]structField, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		index := append(append([]int{}, parentIndex...), i)
+		tag, hasTag := f.Tag.Lookup("redis")
+		if !hasTag {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				fields = append(fields, parseStructFields(f.Type, index)...)
+			}
+			continue
+		}
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     index,
+			omitEmpty: options == "omitempty",
+			json:      isJSONType(f.Type),
+		})
+	}
+	return fields
+}
+
+// 结构体、map、slice 等复合类型用 JSON 存储，实现了 encoding.TextUnmarshaler 的类型（例如 time.Time）和 Float32Vector 除外
+func isJSONType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == float32VectorType || reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array, reflect.Interface:
+		return true
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// src 可以是结构体或者结构体指针
+func structValueOf(src any) (reflect.Value, error) {
+	rv := reflect.ValueOf(src)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("src must be a struct or pointer to struct, got %T.", src)
+	}
+	return rv, nil
+}
+
+// dst 必须是非 nil 的结构体指针
+func structPointerValueOf(dst any) (reflect.Value, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("dst must be a non-nil pointer to struct, got %T.", dst)
+	}
+	return rv.Elem(), nil
+}
+
+// 把结构体中带标签的字段写入哈希表。omitempty 的字段为零值时不写入（也不会删除哈希表中已有的值）
+func (t *HashType) SetStruct(key string, src any) error {
+	rv, err := structValueOf(src)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	fieldValues := make(map[string]any)
+	for _, field := range cachedStructFields(rv.Type()) {
+		fv := rv.FieldByIndex(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		var str string
+		if field.json {
+			b, err := json.Marshal(fv.Interface())
+			if err != nil {
+				return errors.Wrapf(err, "<key: %s, field: %s> json marshal failed.", key, field.name)
+			}
+			str = string(b)
+		} else {
+			str, err = formatValue(fv.Interface())
+			if err != nil {
+				return errors.WithMessagef(err, "<key: %s, field: %s>", key, field.name)
+			}
+		}
+		fieldValues[field.name] = str
+	}
+	if len(fieldValues) == 0 {
+		return nil
+	}
+	return t.SetBatch(key, fieldValues)
+}
+
+// 用 HMGET 读取结构体中带标签的字段，哈希表中不存在的字段保持原值
+func (t *HashType) GetStruct(key string, dst any) error {
+	key = t.prefix.key(key)
+	rv, err := structPointerValueOf(dst)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	fields := cachedStructFields(rv.Type())
+	if len(fields) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+	values, err := t.db.HMGet(t.ctx("getstruct"), key, names...).Result()
+	if err != nil {
+		return errors.Wrapf(err, "<key: %s, fields: %v>", key, names)
+	}
+	for i, field := range fields {
+		str, ok := values[i].(string)
+		if !ok {
+			continue
+		}
+		if err := decodeStructField(rv, field, str, "key: "+key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 把哈希表的字段值写入 rv（结构体）中对应的字段，values 中不存在的字段保持原值。
+// position 是错误信息里字段所在的位置，例如 key: user:1、row: 0
+func decodeStructFields(rv reflect.Value, values map[string]string, position string) error {
+	for _, field := range cachedStructFields(rv.Type()) {
+		str, ok := values[field.name]
+		if !ok {
+			continue
+		}
+		if err := decodeStructField(rv, field, str, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeStructField(rv reflect.Value, field structField, str string, position string) error {
+	fv := rv.FieldByIndex(field.index)
+	if fv.Kind() == reflect.Pointer {
+		fv.Set(reflect.New(fv.Type().Elem()))
+		fv = fv.Elem()
+	}
+	var err error
+	if field.json {
+		err = json.Unmarshal([]byte(str), fv.Addr().Interface())
+	} else {
+		err = parseValueTo(str, fv.Addr().Interface())
+	}
+	if err != nil {
+		return errors.Wrapf(err, "<%s, field: %s> string <%s> to %s failed.", position, field.name, str, fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package go_redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type HealthState int32
+
+const (
+	HealthConnecting HealthState = iota // 正在连接，还没有成功过
+	HealthHealthy                       // ping 正常
+	HealthDegraded                      // ping 变慢或者偶尔失败
+	HealthDown                          // ping 连续失败，或者已经 Close
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthConnecting:
+		return "connecting"
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	case HealthDown:
+		return "down"
+	}
+	return "unknown"
+}
+
+type HealthConfig struct {
+	Interval         time.Duration // ping 间隔，默认 5 秒
+	Timeout          time.Duration // 单次 ping 超时，默认 1 秒
+	DegradedLatency  time.Duration // ping 超过这个耗时算 degraded，默认 200 毫秒
+	FailureThreshold int           // 连续失败多少次算 down，默认 3，次数更少时算 degraded
+}
+
+// 连接失败时按指数退避重试，服务可以先于 Redis 启动
+type ConnectRetryConfig struct {
+	MaxAttempts    int           // 最多尝试次数（包括第一次），0 表示一直重试直到成功或者 Close
+	InitialBackoff time.Duration // 第一次重试前的等待时间，默认 100 毫秒，之后每次翻倍
+	MaxBackoff     time.Duration // 等待时间上限，默认 10 秒
+}
+
+type HealthEvent struct {
+	From    HealthState
+	To      HealthState
+	Err     error         // 最近一次 ping 的错误
+	Latency time.Duration // 最近一次 ping 的耗时
+	Time    time.Time
+}
+
+type healthChecker struct {
+	mu        sync.Mutex
+	state     HealthState
+	failures  int
+	listeners []func(HealthEvent)
+	config    *HealthConfig
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newHealthChecker() *healthChecker {
+	return &healthChecker{
+		state:  HealthConnecting,
+		config: (&HealthConfig{}).withDefaults(),
+		stop:   make(chan struct{}),
+	}
+}
+
+// 当前健康状态
+func (t *RedisType) Health() HealthState {
+	t.health.mu.Lock()
+	defer t.health.mu.Unlock()
+	return t.health.state
+}
+
+// 是否可以接收流量，用于 readiness 探针。healthy 和 degraded 时为 true
+func (t *RedisType) Ready() bool {
+	state := t.Health()
+	return state == HealthHealthy || state == HealthDegraded
+}
+
+// 立即 ping 一次并更新状态，用于 liveness 探针。ping 失败时返回错误
+func (t *RedisType) Healthy(ctx context.Context) error {
+	if t.Db == nil {
+		return errors.New("not connected")
+	}
+	_, err := t.health.check(ctx, t)
+	return err
+}
+
+// 注册状态变化的回调，回调在健康检查的 goroutine 里同步调用，不要阻塞
+func (t *RedisType) OnHealthChange(fn func(event HealthEvent)) {
+	t.health.mu.Lock()
+	defer t.health.mu.Unlock()
+	t.health.listeners = append(t.health.listeners, fn)
+}
+
+func (config *HealthConfig) withDefaults() *HealthConfig {
+	result := *config
+	if result.Interval <= 0 {
+		result.Interval = 5 * time.Second
+	}
+	if result.Timeout <= 0 {
+		result.Timeout = time.Second
+	}
+	if result.DegradedLatency <= 0 {
+		result.DegradedLatency = 200 * time.Millisecond
+	}
+	if result.FailureThreshold <= 0 {
+		result.FailureThreshold = 3
+	}
+	return &result
+}
+
+func (h *healthChecker) setState(state HealthState, err error, latency time.Duration) {
+	h.mu.Lock()
+	from := h.state
+	if from == state {
+		h.mu.Unlock()
+		return
+	}
+	h.state = state
+	listeners := h.listeners
+	h.mu.Unlock()
+	event := HealthEvent{
+		From:    from,
+		To:      state,
+		Err:     err,
+		Latency: latency,
+		Time:    time.Now(),
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// ping 一次，根据结果更新状态
+func (h *healthChecker) check(ctx context.Context, t *RedisType) (HealthState, error) {
+	h.mu.Lock()
+	config := h.config
+	h.mu.Unlock()
+	ctx, cancel := context.WithTimeout(withOperation(ctx, "health.ping"), config.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := t.Db.Ping(ctx).Err()
+	latency := time.Since(start)
+
+	h.mu.Lock()
+	if err != nil {
+		h.failures++
+	} else {
+		h.failures = 0
+	}
+	failures := h.failures
+	h.mu.Unlock()
+
+	var state HealthState
+	switch {
+	case err != nil && failures >= config.FailureThreshold:
+		state = HealthDown
+	case err != nil, latency > config.DegradedLatency:
+		state = HealthDegraded
+	default:
+		state = HealthHealthy
+	}
+	h.setState(state, err, latency)
+	if err != nil {
+		return state, errors.Wrap(err, "")
+	}
+	return state, nil
+}
+
+func (h *healthChecker) start(t *RedisType) {
+	h.mu.Lock()
+	h.done = make(chan struct{})
+	stop, done, config := h.stop, h.done, h.config
+	h.mu.Unlock()
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			from := t.Health()
+			state, err := h.check(context.Background(), t)
+			if state != from {
+				if err != nil {
+					t.logger.WarnF(`Redis health changed from %s to %s. err: %s`, from, state, err)
+				} else {
+					t.logger.InfoF(`Redis health changed from %s to %s.`, from, state)
+				}
+			}
+		}
+	}()
+}
+
+// 连接前调用，重新开始计数
+func (h *healthChecker) reset(config *HealthConfig) {
+	if config == nil {
+		config = &HealthConfig{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	select {
+	case <-h.stop:
+		h.stop = make(chan struct{})
+	default:
+	}
+	h.config = config.withDefaults()
+	h.failures = 0
+}
+
+func (h *healthChecker) close() {
+	h.mu.Lock()
+	select {
+	case <-h.stop:
+	default:
+		close(h.stop)
+	}
+	done := h.done
+	h.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+	h.setState(HealthDown, errors.New("closed"), 0)
+}
+
+// 按退避时间等待，Close 时提前返回 false
+func (h *healthChecker) wait(d time.Duration) bool {
+	h.mu.Lock()
+	stop := h.stop
+	h.mu.Unlock()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (config *ConnectRetryConfig) backoff(attempt int) time.Duration {
+	backoff := config.InitialBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
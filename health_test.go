@@ -0,0 +1,102 @@
+package go_redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+)
+
+func waitHealth(t *testing.T, instance *RedisType, state HealthState) {
+	deadline := time.Now().Add(2 * time.Second)
+	for instance.Health() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("health is %s, want %s", instance.Health(), state)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealth_StateChanges(t *testing.T) {
+	server := miniredis.RunT(t)
+	instance := New(&i_logger.DefaultLogger, 5*time.Second)
+	var mu sync.Mutex
+	events := make([]HealthEvent, 0)
+	instance.OnHealthChange(func(event HealthEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	go_test_.Equal(t, false, instance.Ready())
+	err := instance.Connect(&Configuration{
+		Url: server.Addr(),
+		Health: &HealthConfig{
+			Interval:         10 * time.Millisecond,
+			FailureThreshold: 2,
+		},
+	})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, HealthHealthy, instance.Health())
+	go_test_.Equal(t, true, instance.Ready())
+	go_test_.Equal(t, nil, instance.Healthy(context.Background()))
+
+	server.SetError("LOADING")
+	waitHealth(t, instance, HealthDown)
+	go_test_.Equal(t, false, instance.Ready())
+	go_test_.NotEqual(t, nil, instance.Healthy(context.Background()))
+
+	server.SetError("")
+	waitHealth(t, instance, HealthHealthy)
+
+	instance.Close()
+	go_test_.Equal(t, HealthDown, instance.Health())
+
+	mu.Lock()
+	defer mu.Unlock()
+	states := make([]HealthState, 0, len(events))
+	for _, event := range events {
+		states = append(states, event.To)
+	}
+	go_test_.Equal(t, []HealthState{HealthHealthy, HealthDegraded, HealthDown, HealthHealthy, HealthDown}, states)
+	go_test_.NotEqual(t, nil, events[1].Err)
+	go_test_.Equal(t, HealthHealthy, events[1].From)
+}
+
+func TestHealth_ConnectRetry(t *testing.T) {
+	server := miniredis.NewMiniRedis()
+	go_test_.Equal(t, nil, server.Start())
+	addr := server.Addr()
+	server.Close()
+
+	instance := New(&i_logger.DefaultLogger, time.Second)
+	err := instance.Connect(&Configuration{
+		Url:   addr,
+		Retry: &ConnectRetryConfig{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond},
+	})
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, HealthDown, instance.Health())
+	instance.Close()
+
+	// Redis 晚于服务启动
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		go_test_.Equal(t, nil, server.StartAddr(addr))
+	}()
+	t.Cleanup(server.Close)
+	instance = New(&i_logger.DefaultLogger, time.Second)
+	err = instance.Connect(&Configuration{
+		Url:   addr,
+		Retry: &ConnectRetryConfig{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond},
+	})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, instance.Ready())
+	instance.Close()
+
+	go_test_.Equal(t, 10*time.Millisecond, (&ConnectRetryConfig{InitialBackoff: 10 * time.Millisecond}).backoff(1))
+	go_test_.Equal(t, 40*time.Millisecond, (&ConnectRetryConfig{InitialBackoff: 10 * time.Millisecond}).backoff(3))
+	go_test_.Equal(t, 10*time.Second, (&ConnectRetryConfig{}).backoff(100))
+}
@@ -0,0 +1,296 @@
+package go_redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	t_logger "github.com/pefish/go-interface/t-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const LoggerHookName = "logger"
+
+type CommandInfo struct {
+	Name      string        // 小写命令名，例如 get、hset。pipeline 为 pipeline，事务为 multi，建立连接为 dial
+	Operation string        // 发起命令的封装方法，例如 hash.get，直接通过 Db 执行的命令为空
+	Args      []any         // 完整参数（包括命令名），dial 时为 network 和 addr
+	Keys      []string      // 涉及的 key，无法确定时为空
+	Cmds      []redis.Cmder // pipeline、事务中的所有命令
+	Pipeline  bool
+}
+
+type CommandEvent struct {
+	*CommandInfo
+	Duration time.Duration
+	Err      error  // key 不存在时是 redis.Nil
+	Addr     string // 命令转发到副本执行时是副本地址，在主库执行时为空
+}
+
+// 命令钩子，覆盖所有命令、pipeline 和建立连接。Before 返回的 context 会传给后续钩子和 After
+type Hook struct {
+	Name   string // RemoveHook 时使用
+	Before func(ctx context.Context, info *CommandInfo) context.Context
+	After  func(ctx context.Context, event *CommandEvent)
+}
+
+// 实现 redis.Hook，按添加顺序调用 Before，逆序调用 After
+type hookChain struct {
+	mu    sync.RWMutex
+	hooks []Hook
+}
+
+// 添加钩子，连接前后都可以调用。默认带有一个名为 LoggerHookName 的调试日志钩子
+func (t *RedisType) AddHook(hook Hook) {
+	t.hooks.mu.Lock()
+	defer t.hooks.mu.Unlock()
+	t.hooks.hooks = append(t.hooks.hooks, hook)
+}
+
+// 按名字移除钩子，返回是否找到
+func (t *RedisType) RemoveHook(name string) bool {
+	t.hooks.mu.Lock()
+	defer t.hooks.mu.Unlock()
+	for i, hook := range t.hooks.hooks {
+		if hook.Name == name {
+			t.hooks.hooks = append(t.hooks.hooks[:i:i], t.hooks.hooks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *hookChain) snapshot() []Hook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.hooks
+}
+
+func (c *hookChain) run(ctx context.Context, info *CommandInfo, do func(ctx context.Context) error) error {
+	hooks := c.snapshot()
+	if len(hooks) == 0 {
+		return do(ctx)
+	}
+	for _, hook := range hooks {
+		if hook.Before != nil {
+			ctx = hook.Before(ctx, info)
+		}
+	}
+	// 副本路由在最内层执行，通过 ctx 把实际执行命令的地址带回来
+	var addr string
+	start := time.Now()
+	err := do(context.WithValue(ctx, commandAddrKey{}, &addr))
+	event := &CommandEvent{
+		CommandInfo: info,
+		Duration:    time.Since(start),
+		Err:         err,
+		Addr:        addr,
+	}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i].After != nil {
+			hooks[i].After(ctx, event)
+		}
+	}
+	return err
+}
+
+func (c *hookChain) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var conn net.Conn
+		err := c.run(ctx, &CommandInfo{
+			Name: "dial",
+			Args: []any{network, addr},
+		}, func(ctx context.Context) error {
+			var err error
+			conn, err = next(ctx, network, addr)
+			return err
+		})
+		return conn, err
+	}
+}
+
+func (c *hookChain) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return c.run(ctx, &CommandInfo{
+			Name:      cmd.Name(),
+			Operation: OperationFromContext(ctx),
+			Args:      cmd.Args(),
+			Keys:      commandKeys(cmd.Name(), cmd.Args()),
+		}, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (c *hookChain) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		name := "pipeline"
+		if len(cmds) > 0 && cmds[0].Name() == "multi" {
+			name = "multi"
+		}
+		keys := make([]string, 0)
+		for _, cmd := range cmds {
+			keys = append(keys, commandKeys(cmd.Name(), cmd.Args())...)
+		}
+		return c.run(ctx, &CommandInfo{
+			Name:      name,
+			Operation: OperationFromContext(ctx),
+			Keys:      keys,
+			Cmds:      cmds,
+			Pipeline:  true,
+		}, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+type commandAddrKey struct{}
+
+func setCommandAddr(ctx context.Context, addr string) {
+	if p, ok := ctx.Value(commandAddrKey{}).(*string); ok {
+		*p = addr
+	}
+}
+
+type operationKey struct{}
+
+func withOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// 发起命令的封装方法名，例如 hash.get、key.del、lock.release
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
+
+// 从参数中找出 key，只处理常见命令，其他命令默认第一个参数是 key
+func commandKeys(name string, args []any) []string {
+	indexes := commandKeyIndexes(name, args)
+	if indexes == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		keys = append(keys, argString(args[i]))
+	}
+	return keys
+}
+
+// key 在 args（包括命令名）中的下标
+func commandKeyIndexes(name string, args []any) []int {
+	// RediSearch 命令的第一个参数是索引名，不是 key
+	if strings.HasPrefix(name, "ft.") {
+		return nil
+	}
+	switch name {
+	case "ping", "echo", "publish", "subscribe", "psubscribe", "unsubscribe", "punsubscribe", "keys", "scan",
+		"client", "info", "select", "hello", "auth", "function", "script", "command", "dbsize", "flushdb",
+		"flushall", "config", "quit", "multi", "exec", "discard", "time":
+		return nil
+	case "del", "exists", "unlink", "touch", "mget", "watch", "pfcount", "pfmerge", "sinter", "sunion", "sdiff":
+		return argIndexes(1, len(args), 1)
+	case "mset", "msetnx":
+		return argIndexes(1, len(args), 2)
+	case "bitop":
+		// BITOP operation destkey key [key ...]
+		return argIndexes(2, len(args), 1)
+	case "json.mget":
+		// JSON.MGET key [key ...] path
+		return argIndexes(1, len(args)-1, 1)
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		if len(args) < 3 {
+			return nil
+		}
+		numKeys, err := strconv.Atoi(argString(args[2]))
+		if err != nil || 3+numKeys > len(args) {
+			return nil
+		}
+		return argIndexes(3, 3+numKeys, 1)
+	}
+	if len(args) < 2 {
+		return nil
+	}
+	return []int{1}
+}
+
+func argIndexes(start int, end int, step int) []int {
+	if end <= start {
+		return nil
+	}
+	results := make([]int, 0, (end-start+step-1)/step)
+	for i := start; i < end; i += step {
+		results = append(results, i)
+	}
+	return results
+}
+
+func argString(arg any) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	str, err := formatValue(arg)
+	if err != nil {
+		return ``
+	}
+	return str
+}
+
+func argStrings(args []any) []string {
+	results := make([]string, 0, len(args))
+	for _, arg := range args {
+		results = append(results, argString(arg))
+	}
+	return results
+}
+
+// 默认的调试日志钩子，所有命令的日志都从这里输出，值按 SetLogRedaction 的配置脱敏
+func newLoggerHook(logger i_logger.ILogger, redactor *atomic.Pointer[logRedactor]) Hook {
+	return Hook{
+		Name: LoggerHookName,
+		After: func(ctx context.Context, event *CommandEvent) {
+			// 每条命令都会走到这里，非 debug 级别时不做脱敏和参数拼接
+			if logger.Level() != t_logger.Level_DEBUG {
+				return
+			}
+			r := redactor.Load()
+			prefix := keyPrefixFromContext(ctx)
+			var b strings.Builder
+			b.WriteString(`Redis `)
+			b.WriteString(event.Name)
+			b.WriteString(`. args: `)
+			if event.Pipeline {
+				for i, cmd := range event.Cmds {
+					if i > 0 {
+						b.WriteString(`; `)
+					}
+					b.WriteString(cmd.Name())
+					if args := r.args(prefix, cmd.Name(), cmd.Args()); args != `` {
+						b.WriteString(` `)
+						b.WriteString(args)
+					}
+				}
+			} else if event.Name == "dial" {
+				b.WriteString(strings.Join(argStrings(event.Args), ` `))
+			} else {
+				b.WriteString(r.args(prefix, event.Name, event.Args))
+			}
+			b.WriteString(`, duration: `)
+			b.WriteString(event.Duration.String())
+			if event.Err != nil && !errors.Is(event.Err, redis.Nil) {
+				b.WriteString(`, err: `)
+				b.WriteString(event.Err.Error())
+			}
+			logger.Debug(b.String())
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package go_redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	go_test_ "github.com/pefish/go-test"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestHook_Chain(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	go_test_.Equal(t, true, instance.RemoveHook(LoggerHookName))
+	go_test_.Equal(t, false, instance.RemoveHook(LoggerHookName))
+
+	type ctxKey struct{}
+	var mu sync.Mutex
+	events := make([]*CommandEvent, 0)
+	instance.AddHook(Hook{
+		Name: "test",
+		Before: func(ctx context.Context, info *CommandInfo) context.Context {
+			return context.WithValue(ctx, ctxKey{}, info.Name)
+		},
+		After: func(ctx context.Context, event *CommandEvent) {
+			go_test_.Equal(t, event.Name, ctx.Value(ctxKey{}))
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		},
+	})
+
+	go_test_.Equal(t, nil, instance.String.Set("a", "1", 0))
+	_, err := instance.String.Get("b")
+	go_test_.Equal(t, nil, err)
+	_, err = instance.Db.Pipelined(context.Background(), func(p redis.Pipeliner) error {
+		p.Get(context.Background(), "a")
+		p.Del(context.Background(), "a", "b")
+		return nil
+	})
+	go_test_.Equal(t, nil, err)
+
+	go_test_.Equal(t, 3, len(events))
+	go_test_.Equal(t, "set", events[0].Name)
+	go_test_.Equal(t, []string{"a"}, events[0].Keys)
+	go_test_.Equal(t, redis.Nil, events[1].Err)
+	go_test_.Equal(t, true, events[2].Pipeline)
+	go_test_.Equal(t, []string{"a", "a", "b"}, events[2].Keys)
+}
+
+func TestHook_CommandKeys(t *testing.T) {
+	go_test_.Equal(t, []string{"k"}, commandKeys("get", []any{"get", "k"}))
+	go_test_.Equal(t, []string{"a", "b"}, commandKeys("mset", []any{"mset", "a", "1", "b", "2"}))
+	go_test_.Equal(t, []string{"dest", "a", "b"}, commandKeys("bitop", []any{"bitop", "AND", "dest", "a", "b"}))
+	go_test_.Equal(t, []string{"a", "b"}, commandKeys("json.mget", []any{"json.mget", "a", "b", "$.name"}))
+	go_test_.Equal(t, 0, len(commandKeys("json.mget", []any{"json.mget"})))
+	go_test_.Equal(t, 0, len(commandKeys("ft.search", []any{"ft.search", "idx", "*"})))
+}
@@ -0,0 +1,65 @@
+package go_redis
+
+import (
+	"context"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// HyperLogLog 用固定的约 12KB 内存做近似去重计数，标准误差 0.81%。适合统计 UV 等不需要精确值、也不需要列出成员的场景
+type HyperLogLogType struct {
+	db      *redis.Client
+	logger  i_logger.ILogger
+	prefix  keyPrefix
+	baseCtx context.Context
+}
+
+// 添加元素，返回估计值是否发生了变化
+func (t *HyperLogLogType) Add(key string, elements ...string) (bool, error) {
+	key = t.prefix.key(key)
+	rawElements := make([]any, 0, len(elements))
+	for _, element := range elements {
+		rawElements = append(rawElements, element)
+	}
+	result, err := t.db.PFAdd(t.ctx("add"), key, rawElements...).Result()
+	if err != nil {
+		return false, errors.Wrapf(err, "<key: %s>", key)
+	}
+	return result == 1, nil
+}
+
+// 返回去重后的元素个数的估计值，key 不存在时按空集合计算。多个 key 时直接返回服务端 PFCOUNT 的结果（Redis 上是并集的估计值），
+// 需要确定按并集计算时先 Merge 再 Count
+func (t *HyperLogLogType) Count(keys ...string) (uint64, error) {
+	if len(keys) == 0 {
+		return 0, errors.New("keys is empty.")
+	}
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, t.prefix.key(key))
+	}
+	result, err := t.db.PFCount(t.ctx("count"), prefixed...).Result()
+	if err != nil {
+		return 0, errors.Wrapf(err, "<keys: %v>", prefixed)
+	}
+	return uint64(result), nil
+}
+
+// 把 sources 合并到 dest，dest 已存在时也会参与合并
+func (t *HyperLogLogType) Merge(dest string, sources ...string) error {
+	dest = t.prefix.key(dest)
+	prefixed := make([]string, 0, len(sources))
+	for _, source := range sources {
+		prefixed = append(prefixed, t.prefix.key(source))
+	}
+	if err := t.db.PFMerge(t.ctx("merge"), dest, prefixed...).Err(); err != nil {
+		return errors.Wrapf(err, "<dest: %s> <sources: %v>", dest, prefixed)
+	}
+	return nil
+}
+
+func (t *HyperLogLogType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "hyperloglog."+operation)
+}
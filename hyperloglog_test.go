@@ -0,0 +1,84 @@
+package go_redis
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	go_test_ "github.com/pefish/go-test"
+)
+
+func addHyperLogLogRange(t *testing.T, instance *RedisType, key string, from int, to int) {
+	batch := make([]string, 0, 1000)
+	for i := from; i < to; i++ {
+		batch = append(batch, "user:"+strconv.Itoa(i))
+		if len(batch) == cap(batch) || i == to-1 {
+			_, err := instance.HyperLogLog.Add(key, batch...)
+			go_test_.Equal(t, nil, err)
+			batch = batch[:0]
+		}
+	}
+}
+
+// 标准误差 0.81%，取 3 倍左右作为允许的误差
+func assertHyperLogLogError(t *testing.T, count uint64, want int) {
+	relative := math.Abs(float64(count)-float64(want)) / float64(want)
+	if relative > 0.025 {
+		t.Fatalf("count %d, want %d, relative error %.4f", count, want, relative)
+	}
+}
+
+func TestHyperLogLogType_Add(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	changed, err := instance.HyperLogLog.Add("visitors", "a", "b", "c")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, changed)
+	changed, err = instance.HyperLogLog.Add("visitors", "a", "b")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, changed)
+
+	count, err := instance.HyperLogLog.Count("visitors")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(3), count)
+	count, err = instance.HyperLogLog.Count("missing")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(0), count)
+
+	_, err = instance.HyperLogLog.Count()
+	go_test_.NotEqual(t, nil, err)
+
+	err = instance.String.Set("plain", "value", 0)
+	go_test_.Equal(t, nil, err)
+	_, err = instance.HyperLogLog.Add("plain", "a")
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestHyperLogLogType_LargeInput(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	view := instance.WithPrefix("uv:")
+
+	addHyperLogLogRange(t, view, "day1", 0, 100000)
+	addHyperLogLogRange(t, view, "day2", 50000, 150000)
+	// 重复添加不影响结果
+	addHyperLogLogRange(t, view, "day1", 0, 10000)
+
+	count, err := view.HyperLogLog.Count("day1")
+	go_test_.Equal(t, nil, err)
+	assertHyperLogLogError(t, count, 100000)
+
+	// miniredis 的多 key PFCOUNT 是把各自的估计值相加，这里只用不相交的集合检查，有交集的并集由 Merge 检查
+	addHyperLogLogRange(t, view, "day3", 150000, 200000)
+	count, err = view.HyperLogLog.Count("day1", "day3")
+	go_test_.Equal(t, nil, err)
+	assertHyperLogLogError(t, count, 150000)
+
+	go_test_.Equal(t, nil, view.HyperLogLog.Merge("week", "day1", "day2"))
+	count, err = view.HyperLogLog.Count("week")
+	go_test_.Equal(t, nil, err)
+	assertHyperLogLogError(t, count, 150000)
+
+	count, err = instance.HyperLogLog.Count("uv:week")
+	go_test_.Equal(t, nil, err)
+	assertHyperLogLogError(t, count, 150000)
+}
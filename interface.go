@@ -0,0 +1,245 @@
+package go_redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisType 的接口，方便调用方替换成 mock（见 redismock 包）。
+// WithPrefix、WithContext、Primary 返回具体类型，不在接口里，需要视图时先创建视图再赋值给接口：
+//
+//	var r go_redis.IRedis = instance.WithContext(ctx)
+//
+// 子类型通过 Strings、Hashes 等方法获取。GetAs、SetValue 等泛型函数需要具体类型，不能 mock
+type IRedis interface {
+	Connect(configuration *Configuration) error
+	Close()
+	SetCodec(config *CodecConfig) error
+	AddHook(hook Hook)
+	RemoveHook(name string) bool
+	EnableMetrics(options *MetricsOptions) *Metrics
+	EnableTracing(options *TracingOptions)
+	SetLogRedaction(redaction *LogRedaction) error
+	RegisterScripts(scripts ...ScriptSource) error
+	LoadFunctionLibrary(library *FunctionLibrary) (loaded_ bool, err_ error)
+	ListFunctionLibraries(pattern string) ([]FunctionLibraryInfo, error)
+	DeleteFunctionLibrary(name string) (bool, error)
+	ClientCacheStats() ClientCacheStats
+	Health() HealthState
+	Ready() bool
+	Healthy(ctx context.Context) error
+	OnHealthChange(fn func(event HealthEvent))
+
+	Strings() IString
+	Hashes() IHash
+	Lists() IList
+	Sets() ISet
+	OrderSets() IOrderSet
+	Bitmaps() IBitmap
+	HyperLogLogs() IHyperLogLog
+	Geos() IGeo
+	JSONDocs() IJSONDoc
+	Searches() ISearch
+
+	Del(key string) (bool, error)
+	Exists(key string) (bool, error)
+	Keys(pattern string) ([]string, error)
+	Scan(cursor uint64, match string, count int64) (keys_ []string, nextCursor_ uint64, err_ error)
+	Expire(key string, expiration time.Duration) error
+	Publish(channel string, message string) (receivedSubscriberCount_ uint64, err_ error)
+	Subscribe(channel string) <-chan *redis.Message
+	GetLock(key string, value string, expiration time.Duration) (bool, error)
+	ReleaseLock(key string, value string) error
+}
+
+type IString interface {
+	Set(key string, value string, expiration time.Duration) error
+	SetUint64(key string, value uint64, expiration time.Duration) error
+	SetNX(key string, value string, expiration time.Duration) (bool, error)
+	Get(key string) (string, error)
+	GetOk(key string) (result_ string, found_ bool, err_ error)
+	GetUint64(key string) (uint64, error)
+	GetUint64Ok(key string) (result_ uint64, found_ bool, err_ error)
+	GetFloat64(key string) (float64, error)
+	GetFloat64Ok(key string) (result_ float64, found_ bool, err_ error)
+	IncrBy(key string, increment int64) (int64, error)
+}
+
+type IHash interface {
+	Exists(key, field string) (bool, error)
+	Get(key, field string) (string, error)
+	GetOk(key, field string) (result_ string, found_ bool, err_ error)
+	GetBatch(key string, fields []string) ([]any, error)
+	RandomGetFields(key string, count int) ([]string, error)
+	GetUint64(key, field string) (uint64, error)
+	GetUint64Ok(key, field string) (result_ uint64, found_ bool, err_ error)
+	GetFloat64(key, field string) (float64, error)
+	GetFloat64Ok(key, field string) (result_ float64, found_ bool, err_ error)
+	GetAll(key string) (map[string]string, error)
+	Set(key, field, value string) error
+	SetBatch(key string, fieldValues map[string]any) error
+	SetUint64(key, field string, value uint64) error
+	SetNX(key, field string, value string) (bool, error)
+	Del(key, field string) (bool, error)
+	DelBatch(key string, fields []string) (int64, error)
+	Len(key string) (int64, error)
+	Fields(key string) ([]string, error)
+	Values(key string) ([]string, error)
+	IncrBy(key string, field string, increment int64) (int64, error)
+	SetStruct(key string, src any) error
+	GetStruct(key string, dst any) error
+	SetVector(key, field string, vector []float32) error
+	GetVector(key, field string) ([]float32, error)
+	GetVectorOk(key, field string) (result_ []float32, found_ bool, err_ error)
+}
+
+type IList interface {
+	LPush(key string, values ...string) (listLength_ uint64, err_ error)
+	LPushUint64(key string, values ...uint64) (listLength_ uint64, err_ error)
+	RPush(key string, values ...string) (listLength_ uint64, err_ error)
+	RPushUint64(key string, values ...uint64) (listLength_ uint64, err_ error)
+	LPop(key string) (string, error)
+	LPopOk(key string) (result_ string, found_ bool, err_ error)
+	LPopUint64(key string) (uint64, error)
+	LPopUint64Ok(key string) (result_ uint64, found_ bool, err_ error)
+	RPop(key string) (string, error)
+	RPopOk(key string) (result_ string, found_ bool, err_ error)
+	RPopUint64(key string) (uint64, error)
+	RPopUint64Ok(key string) (result_ uint64, found_ bool, err_ error)
+	Len(key string) (uint64, error)
+	Range(key string, start int64, stop int64) ([]string, error)
+	ListAll(key string) ([]string, error)
+	ListAllUint64(key string) ([]uint64, error)
+	Get(key string, index int) (string, error)
+	GetOk(key string, index int) (result_ string, found_ bool, err_ error)
+	GetUint64(key string, index int) (uint64, error)
+	GetUint64Ok(key string, index int) (result_ uint64, found_ bool, err_ error)
+	Set(key string, index int, value string) error
+	SetUint64(key string, index int, value uint64) error
+	LTrim(key string, start int64, stop int64) error
+}
+
+type ISet interface {
+	Add(key string, member string) error
+	AddBatch(key string, members []any) error
+	Members(key string) ([]string, error)
+	IsMember(key string, member string) (bool, error)
+	Remove(key string, members ...string) error
+}
+
+type IOrderSet interface {
+	Add(key string, member string, score float64) error
+	AddBatch(key string, members []redis.Z) error
+	Remove(key string, member string) (bool, error)
+	RemRangeByScore(key string, min float64, max float64) error
+	Count(key string, min float64, max float64) (int64, error)
+	TotalCount(key string) (int64, error)
+	IncrBy(key string, member string, increment float64) (float64, error)
+	Range(key string, start int64, stop int64) ([]string, error)
+	RevRange(key string, start int64, stop int64) ([]string, error)
+	RevRangeWithScores(key string, start int64, stop int64) ([]redis.Z, error)
+	RangeByScore(key string, rangeBy RangeBy) ([]string, error)
+	RevRangeByScore(key string, rangeBy RangeBy) ([]string, error)
+	RevRangeByScoreWithScores(key string, rangeBy RangeBy) ([]redis.Z, error)
+	Score(key string, member string) (float64, error)
+	ScoreOk(key string, member string) (result_ float64, found_ bool, err_ error)
+}
+
+type IBitmap interface {
+	SetBit(key string, offset uint64, value bool) (old_ bool, err_ error)
+	GetBit(key string, offset uint64) (bool, error)
+	Count(key string, bitRange *BitRange) (uint64, error)
+	BitPos(key string, bit bool, bitRange *BitRange) (int64, error)
+	BitOp(op BitOperation, dest string, keys ...string) (int64, error)
+	BitField(key string) *BitFieldBuilder
+}
+
+type IHyperLogLog interface {
+	Add(key string, elements ...string) (bool, error)
+	Count(keys ...string) (uint64, error)
+	Merge(dest string, sources ...string) error
+}
+
+type IGeo interface {
+	Add(key string, options *GeoAddOptions, locations ...GeoLocation) (int64, error)
+	Pos(key string, members ...string) ([]*GeoPosition, error)
+	Dist(key string, member1 string, member2 string, unit GeoUnit) (dist_ float64, found_ bool, err_ error)
+	Hash(key string, members ...string) ([]string, error)
+	Search(key string) *GeoSearchBuilder
+}
+
+type IJSONDoc interface {
+	Set(key string, path string, value any, options *JSONSetOptions) (set_ bool, err_ error)
+	Get(key string, paths ...string) (json.RawMessage, error)
+	MGet(path string, keys ...string) ([]json.RawMessage, error)
+	Del(key string, path string) (int64, error)
+	NumIncrBy(key string, path string, value float64) (json.RawMessage, error)
+	ArrAppend(key string, path string, values ...any) ([]int64, error)
+}
+
+type ISearch interface {
+	CreateIndex(index string, schema any, options *IndexOptions) error
+	DropIndex(index string, deleteDocs bool) (bool, error)
+	Indexes() ([]string, error)
+	Query(index string, query string) *SearchQuery
+	Aggregate(index string, query string) *AggregateQuery
+	CreateVectorIndex(index string, field string, vector VectorFieldOptions, filters []SearchField, options *IndexOptions) error
+	KNN(index string, field string, vector []float32, k int) *KNNQuery
+}
+
+var (
+	_ IRedis       = (*RedisType)(nil)
+	_ IString      = (*StringType)(nil)
+	_ IHash        = (*HashType)(nil)
+	_ IList        = (*ListType)(nil)
+	_ ISet         = (*SetType)(nil)
+	_ IOrderSet    = (*OrderSetType)(nil)
+	_ IBitmap      = (*BitmapType)(nil)
+	_ IHyperLogLog = (*HyperLogLogType)(nil)
+	_ IGeo         = (*GeoType)(nil)
+	_ IJSONDoc     = (*JSONDocType)(nil)
+	_ ISearch      = (*SearchType)(nil)
+)
+
+func (t *RedisType) Strings() IString {
+	return t.String
+}
+
+func (t *RedisType) Hashes() IHash {
+	return t.Hash
+}
+
+func (t *RedisType) Lists() IList {
+	return t.List
+}
+
+func (t *RedisType) Sets() ISet {
+	return t.Set
+}
+
+func (t *RedisType) OrderSets() IOrderSet {
+	return t.OrderSet
+}
+
+func (t *RedisType) Bitmaps() IBitmap {
+	return t.Bitmap
+}
+
+func (t *RedisType) HyperLogLogs() IHyperLogLog {
+	return t.HyperLogLog
+}
+
+func (t *RedisType) Geos() IGeo {
+	return t.Geo
+}
+
+func (t *RedisType) JSONDocs() IJSONDoc {
+	return t.JSON
+}
+
+func (t *RedisType) Searches() ISearch {
+	return t.Search
+}
@@ -0,0 +1,271 @@
+package go_redis
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// 服务端没有加载 RedisJSON 模块，用 errors.Is 判断
+var ErrJSONModuleNotLoaded = errors.New("redis: RedisJSON module not loaded")
+
+// RedisJSON 文档，可以按 JSONPath 读写文档的一部分，不用每次改一个字段都重写整个值。
+// path 以 $ 开头时是 JSONPath，结果是所有匹配值组成的数组；否则是旧的路径语法（例如 .a.b），结果是单个值。path 为空时是 $
+type JSONDocType struct {
+	db      *redis.Client
+	logger  i_logger.ILogger
+	prefix  keyPrefix
+	baseCtx context.Context
+}
+
+type JSONSetOptions struct {
+	NX bool // 路径不存在时才设置
+	XX bool // 路径已存在时才设置
+}
+
+func jsonPath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
+
+func isJSONPath(path string) bool {
+	return strings.HasPrefix(path, "$")
+}
+
+func (t *JSONDocType) wrapErr(err error, format string, args ...any) error {
+	return wrapModuleErr(err, "json.", ErrJSONModuleNotLoaded, format, args...)
+}
+
+// 模块命令的错误。服务端不认识 commandPrefix 开头的命令时说明模块没有加载，返回 notLoaded
+func wrapModuleErr(err error, commandPrefix string, notLoaded error, format string, args ...any) error {
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "unknown command") && strings.Contains(message, commandPrefix) {
+		return errors.Wrapf(notLoaded, format, args...)
+	}
+	return errors.Wrapf(err, format, args...)
+}
+
+// 把 value 序列化成 JSON 后设置到 path，json.RawMessage 原样写入。新建文档时 path 必须是根路径。
+// options 可以为 nil，NX、XX 的条件不满足时返回 false
+func (t *JSONDocType) Set(key string, path string, value any, options *JSONSetOptions) (set_ bool, err_ error) {
+	key = t.prefix.key(key)
+	path = jsonPath(path)
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, errors.Wrapf(err, "<key: %s> <path: %s> marshal value failed", key, path)
+	}
+	args := []any{"JSON.SET", key, path, string(data)}
+	if options != nil {
+		if options.NX && options.XX {
+			return false, errors.Errorf("<key: %s> NX and XX are mutually exclusive.", key)
+		}
+		if options.NX {
+			args = append(args, "NX")
+		}
+		if options.XX {
+			args = append(args, "XX")
+		}
+	}
+	if err := t.db.Do(t.ctx("set"), args...).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, t.wrapErr(err, "<key: %s> <path: %s>", key, path)
+	}
+	return true, nil
+}
+
+// 返回 path 处的 JSON，key 不存在时返回 nil。多个 path 时返回以 path 为 key 的对象
+func (t *JSONDocType) Get(key string, paths ...string) (json.RawMessage, error) {
+	key = t.prefix.key(key)
+	args := []any{"JSON.GET", key}
+	for _, path := range paths {
+		args = append(args, path)
+	}
+	result, err := t.db.Do(t.ctx("get"), args...).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, t.wrapErr(err, "<key: %s> <paths: %v>", key, paths)
+	}
+	return json.RawMessage(result), nil
+}
+
+// 返回多个 key 在 path 处的 JSON，和 keys 一一对应，key 不存在时为 nil
+func (t *JSONDocType) MGet(path string, keys ...string) ([]json.RawMessage, error) {
+	path = jsonPath(path)
+	args := []any{"JSON.MGET"}
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key = t.prefix.key(key)
+		prefixed = append(prefixed, key)
+		args = append(args, key)
+	}
+	args = append(args, path)
+	values, err := t.db.Do(t.ctx("mget"), args...).Slice()
+	if err != nil {
+		return nil, t.wrapErr(err, "<keys: %v> <path: %s>", prefixed, path)
+	}
+	results := make([]json.RawMessage, 0, len(values))
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			results = append(results, nil)
+			continue
+		}
+		results = append(results, json.RawMessage(str))
+	}
+	return results, nil
+}
+
+// 删除 path 处的值，path 是根路径时删除整个 key，返回删除的值的个数
+func (t *JSONDocType) Del(key string, path string) (int64, error) {
+	key = t.prefix.key(key)
+	path = jsonPath(path)
+	result, err := t.db.Do(t.ctx("del"), "JSON.DEL", key, path).Int64()
+	if err != nil {
+		return 0, t.wrapErr(err, "<key: %s> <path: %s>", key, path)
+	}
+	return result, nil
+}
+
+// 把 path 处的数字加上 value，返回新的值。JSONPath 时是所有匹配值的新值组成的数组，不是数字的匹配值为 null
+func (t *JSONDocType) NumIncrBy(key string, path string, value float64) (json.RawMessage, error) {
+	key = t.prefix.key(key)
+	path = jsonPath(path)
+	result, err := t.db.Do(t.ctx("numincrby"), "JSON.NUMINCRBY", key, path, value).Text()
+	if err != nil {
+		return nil, t.wrapErr(err, "<key: %s> <path: %s>", key, path)
+	}
+	return json.RawMessage(result), nil
+}
+
+// 把 values 序列化成 JSON 后追加到 path 处的数组，返回追加后数组的长度。
+// JSONPath 时和每个匹配值一一对应，不是数组的匹配值为 -1
+func (t *JSONDocType) ArrAppend(key string, path string, values ...any) ([]int64, error) {
+	key = t.prefix.key(key)
+	path = jsonPath(path)
+	if len(values) == 0 {
+		return nil, errors.Errorf("<key: %s> <path: %s> values is empty.", key, path)
+	}
+	args := []any{"JSON.ARRAPPEND", key, path}
+	for _, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "<key: %s> <path: %s> marshal value failed", key, path)
+		}
+		args = append(args, string(data))
+	}
+	result, err := t.db.Do(t.ctx("arrappend"), args...).Result()
+	if err != nil {
+		return nil, t.wrapErr(err, "<key: %s> <path: %s>", key, path)
+	}
+	switch result := result.(type) {
+	case int64:
+		return []int64{result}, nil
+	case []any:
+		lengths := make([]int64, 0, len(result))
+		for _, item := range result {
+			length, ok := item.(int64)
+			if !ok {
+				length = -1
+			}
+			lengths = append(lengths, length)
+		}
+		return lengths, nil
+	}
+	return nil, errors.Errorf("<key: %s> <path: %s> unexpected result %T.", key, path, result)
+}
+
+func (t *JSONDocType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "json."+operation)
+}
+
+// 获取 path 处的值并解码成 T。JSONPath 时取第一个匹配值，需要所有匹配值用 JSONGetAll。key 不存在或没有匹配值时返回零值
+func JSONGet[T any](t *JSONDocType, key string, path string) (T, error) {
+	result, _, err := JSONGetOk[T](t, key, path)
+	return result, err
+}
+
+func JSONGetOk[T any](t *JSONDocType, key string, path string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	path = jsonPath(path)
+	data, err := t.Get(key, path)
+	if err != nil || data == nil {
+		return zero, false, err
+	}
+	return decodeJSONMatch[T](data, path, t.prefix.key(key))
+}
+
+// 获取 JSONPath 的所有匹配值并解码成 T
+func JSONGetAll[T any](t *JSONDocType, key string, path string) ([]T, error) {
+	path = jsonPath(path)
+	if !isJSONPath(path) {
+		return nil, errors.Errorf("<path: %s> is not a JSONPath.", path)
+	}
+	data, err := t.Get(key, path)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var results []T
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.Wrapf(err, "<key: %s> <path: %s> decode failed", t.prefix.key(key), path)
+	}
+	return results, nil
+}
+
+// 获取多个 key 在 path 处的值并解码成 T，和 keys 一一对应，key 不存在或没有匹配值时为 nil
+func JSONMGet[T any](t *JSONDocType, path string, keys ...string) ([]*T, error) {
+	path = jsonPath(path)
+	datas, err := t.MGet(path, keys...)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*T, 0, len(datas))
+	for i, data := range datas {
+		if data == nil {
+			results = append(results, nil)
+			continue
+		}
+		value, found, err := decodeJSONMatch[T](data, path, t.prefix.key(keys[i]))
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			results = append(results, nil)
+			continue
+		}
+		results = append(results, &value)
+	}
+	return results, nil
+}
+
+func decodeJSONMatch[T any](data json.RawMessage, path string, key string) (T, bool, error) {
+	var zero T
+	if !isJSONPath(path) {
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return zero, false, errors.Wrapf(err, "<key: %s> <path: %s> decode failed", key, path)
+		}
+		return value, true, nil
+	}
+	var matches []json.RawMessage
+	if err := json.Unmarshal(data, &matches); err != nil {
+		return zero, false, errors.Wrapf(err, "<key: %s> <path: %s> decode failed", key, path)
+	}
+	if len(matches) == 0 {
+		return zero, false, nil
+	}
+	var value T
+	if err := json.Unmarshal(matches[0], &value); err != nil {
+		return zero, false, errors.Wrapf(err, "<key: %s> <path: %s> decode failed", key, path)
+	}
+	return value, true, nil
+}
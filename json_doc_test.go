@@ -0,0 +1,336 @@
+package go_redis
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/pkg/errors"
+)
+
+// 解析 JSON.* 用到的路径子集：$、$.a.b 以及旧语法 .、.a.b
+func parseFakeJSONPath(path string) (segments []string, isJSONPath bool) {
+	isJSONPath = strings.HasPrefix(path, "$")
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil, isJSONPath
+	}
+	return strings.Split(path, "."), isJSONPath
+}
+
+func fakeJSONLookup(doc any, segments []string) (any, bool) {
+	for _, segment := range segments {
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func fakeJSONMarshal(value any) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// miniredis 不支持 RedisJSON，这里注册 JSONDocType 用到的命令，文档保存在内存里，只支持对象字段路径
+func registerFakeJSON(t *testing.T, m *miniredis.Miniredis) {
+	var mu sync.Mutex
+	docs := make(map[string]any)
+	srv := m.Server()
+	register := func(name string, handler func(c *server.Peer, args []string)) {
+		go_test_.Equal(t, nil, srv.Register(name, func(c *server.Peer, cmd string, args []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			handler(c, args)
+		}))
+	}
+	// 按路径读取，JSONPath 时结果是匹配值组成的数组
+	read := func(key string, path string) (string, bool) {
+		doc, ok := docs[key]
+		if !ok {
+			return "", false
+		}
+		segments, isJSONPath := parseFakeJSONPath(path)
+		value, found := fakeJSONLookup(doc, segments)
+		if isJSONPath {
+			if !found {
+				return "[]", true
+			}
+			return fakeJSONMarshal([]any{value}), true
+		}
+		return fakeJSONMarshal(value), found
+	}
+	// 修改路径处的值，返回是否匹配
+	update := func(key string, path string, f func(value any, exists bool) any) bool {
+		segments, _ := parseFakeJSONPath(path)
+		if len(segments) == 0 {
+			value, exists := docs[key]
+			docs[key] = f(value, exists)
+			return true
+		}
+		parent, found := fakeJSONLookup(docs[key], segments[:len(segments)-1])
+		object, ok := parent.(map[string]any)
+		if !found || !ok {
+			return false
+		}
+		last := segments[len(segments)-1]
+		value, exists := object[last]
+		object[last] = f(value, exists)
+		return true
+	}
+
+	register("JSON.SET", func(c *server.Peer, args []string) {
+		key, path := args[0], args[1]
+		var value any
+		if err := json.Unmarshal([]byte(args[2]), &value); err != nil {
+			c.WriteError("ERR " + err.Error())
+			return
+		}
+		segments, _ := parseFakeJSONPath(path)
+		if _, ok := docs[key]; !ok && len(segments) > 0 {
+			c.WriteError("ERR new objects must be created at the root")
+			return
+		}
+		_, exists := fakeJSONLookup(docs[key], segments)
+		if _, ok := docs[key]; !ok {
+			exists = false
+		}
+		mode := ""
+		if len(args) > 3 {
+			mode = strings.ToUpper(args[3])
+		}
+		if (mode == "NX" && exists) || (mode == "XX" && !exists) {
+			c.WriteNull()
+			return
+		}
+		if !update(key, path, func(any, bool) any { return value }) {
+			c.WriteNull()
+			return
+		}
+		c.WriteOK()
+	})
+	register("JSON.GET", func(c *server.Peer, args []string) {
+		path := "."
+		if len(args) > 1 {
+			path = args[1]
+		}
+		result, ok := read(args[0], path)
+		if !ok {
+			c.WriteNull()
+			return
+		}
+		c.WriteBulk(result)
+	})
+	register("JSON.MGET", func(c *server.Peer, args []string) {
+		keys, path := args[:len(args)-1], args[len(args)-1]
+		c.WriteLen(len(keys))
+		for _, key := range keys {
+			if result, ok := read(key, path); ok {
+				c.WriteBulk(result)
+			} else {
+				c.WriteNull()
+			}
+		}
+	})
+	register("JSON.DEL", func(c *server.Peer, args []string) {
+		key, path := args[0], args[1]
+		segments, _ := parseFakeJSONPath(path)
+		if _, ok := docs[key]; !ok {
+			c.WriteInt(0)
+			return
+		}
+		if len(segments) == 0 {
+			delete(docs, key)
+			c.WriteInt(1)
+			return
+		}
+		parent, _ := fakeJSONLookup(docs[key], segments[:len(segments)-1])
+		object, ok := parent.(map[string]any)
+		if _, exists := object[segments[len(segments)-1]]; !ok || !exists {
+			c.WriteInt(0)
+			return
+		}
+		delete(object, segments[len(segments)-1])
+		c.WriteInt(1)
+	})
+	register("JSON.NUMINCRBY", func(c *server.Peer, args []string) {
+		key, path := args[0], args[1]
+		increment, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			c.WriteError("ERR " + err.Error())
+			return
+		}
+		var result any
+		update(key, path, func(value any, exists bool) any {
+			if number, ok := value.(float64); ok && exists {
+				result = number + increment
+				return result
+			}
+			return value
+		})
+		if _, isJSONPath := parseFakeJSONPath(path); isJSONPath {
+			c.WriteBulk(fakeJSONMarshal([]any{result}))
+			return
+		}
+		if result == nil {
+			c.WriteError("ERR wrong type, expected number")
+			return
+		}
+		c.WriteBulk(fakeJSONMarshal(result))
+	})
+	register("JSON.ARRAPPEND", func(c *server.Peer, args []string) {
+		key, path := args[0], args[1]
+		values := make([]any, 0, len(args)-2)
+		for _, arg := range args[2:] {
+			var value any
+			if err := json.Unmarshal([]byte(arg), &value); err != nil {
+				c.WriteError("ERR " + err.Error())
+				return
+			}
+			values = append(values, value)
+		}
+		length := -1
+		update(key, path, func(value any, exists bool) any {
+			if array, ok := value.([]any); ok && exists {
+				array = append(array, values...)
+				length = len(array)
+				return array
+			}
+			return value
+		})
+		if _, isJSONPath := parseFakeJSONPath(path); isJSONPath {
+			c.WriteLen(1)
+			if length < 0 {
+				c.WriteNull()
+			} else {
+				c.WriteInt(length)
+			}
+			return
+		}
+		if length < 0 {
+			c.WriteError("ERR wrong type, expected array")
+			return
+		}
+		c.WriteInt(length)
+	})
+}
+
+type jsonDocUser struct {
+	Name    string         `json:"name"`
+	Age     int            `json:"age"`
+	Tags    []string       `json:"tags"`
+	Profile map[string]any `json:"profile,omitempty"`
+}
+
+func TestJSONDocType(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	registerFakeJSON(t, m)
+	docs := instance.WithPrefix("doc:").JSON
+
+	set, err := docs.Set("user:1", "$", jsonDocUser{Name: "alice", Age: 30, Tags: []string{"a"}}, nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, set)
+	set, err = docs.Set("user:1", "$", jsonDocUser{Name: "bob"}, &JSONSetOptions{NX: true})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, set)
+	set, err = docs.Set("user:1", "$.name", "carol", &JSONSetOptions{XX: true})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, set)
+	set, err = docs.Set("user:1", "$.email", "carol@example.com", &JSONSetOptions{XX: true})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, set)
+	_, err = docs.Set("user:1", "$", 1, &JSONSetOptions{NX: true, XX: true})
+	go_test_.NotEqual(t, nil, err)
+	_, err = docs.Set("user:2", "", json.RawMessage(`{"name":"dave","age":40,"tags":[]}`), nil)
+	go_test_.Equal(t, nil, err)
+
+	raw, err := docs.Get("user:1", "$.name")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, `["carol"]`, string(raw))
+	raw, err = docs.Get("missing")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, json.RawMessage(nil), raw)
+
+	user, err := JSONGet[jsonDocUser](docs, "user:1", "$")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, jsonDocUser{Name: "carol", Age: 30, Tags: []string{"a"}}, user)
+	name, err := JSONGet[string](docs, "user:1", ".name")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "carol", name)
+	_, found, err := JSONGetOk[string](docs, "user:1", "$.email")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	_, found, err = JSONGetOk[string](docs, "missing", "$.name")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	_, err = JSONGet[int](docs, "user:1", "$.name")
+	go_test_.NotEqual(t, nil, err)
+	ages, err := JSONGetAll[int](docs, "user:1", "$.age")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []int{30}, ages)
+
+	value, err := docs.NumIncrBy("user:1", "$.age", 2)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, `[32]`, string(value))
+	age, err := JSONGet[int](docs, "user:1", ".age")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 32, age)
+
+	lengths, err := docs.ArrAppend("user:1", "$.tags", "b", "c")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []int64{3}, lengths)
+	lengths, err = docs.ArrAppend("user:1", "$.name", "x")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []int64{-1}, lengths)
+	lengths, err = docs.ArrAppend("user:2", ".tags", "z")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []int64{1}, lengths)
+
+	raws, err := docs.MGet("$.name", "user:1", "missing", "user:2")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []json.RawMessage{json.RawMessage(`["carol"]`), nil, json.RawMessage(`["dave"]`)}, raws)
+	users, err := JSONMGet[jsonDocUser](docs, "$", "user:1", "missing", "user:2")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, len(users))
+	go_test_.Equal(t, []string{"a", "b", "c"}, users[0].Tags)
+	go_test_.Equal(t, (*jsonDocUser)(nil), users[1])
+	go_test_.Equal(t, "dave", users[2].Name)
+
+	deleted, err := docs.Del("user:1", "$.tags")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(1), deleted)
+	deleted, err = docs.Del("user:1", "")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(1), deleted)
+	raw, err = instance.JSON.Get("doc:user:1")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, json.RawMessage(nil), raw)
+	raw, err = instance.JSON.Get("doc:user:2", ".name")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, `"dave"`, string(raw))
+}
+
+func TestJSONDocType_ModuleNotLoaded(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	_, err := instance.JSON.Set("doc", "$", map[string]int{"a": 1}, nil)
+	go_test_.Equal(t, true, errors.Is(err, ErrJSONModuleNotLoaded))
+	_, err = JSONGet[int](instance.JSON, "doc", "$.a")
+	go_test_.Equal(t, true, errors.Is(err, ErrJSONModuleNotLoaded))
+	_, err = instance.JSON.MGet("$", "doc")
+	go_test_.Equal(t, true, errors.Is(err, ErrJSONModuleNotLoaded))
+
+	// 其他错误原样返回
+	_, err = instance.JSON.Set("doc", "$", make(chan int), nil)
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, false, errors.Is(err, ErrJSONModuleNotLoaded))
+}
@@ -0,0 +1,249 @@
+package go_redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+type ManagerOptions struct {
+	Timeout      time.Duration // 每个实例的命令超时，默认 5 秒
+	DrainTimeout time.Duration // Reload 后旧实例延迟多久关闭，留给进行中的命令完成，默认 5 秒
+}
+
+// 管理多个按名字区分的 RedisType，第一次 Get 时才连接。Get 可以并发调用
+//
+//	manager := go_redis.NewManager(logger, map[string]*go_redis.Configuration{
+//		"session": {Url: "10.0.0.1"},
+//		"cache":   {Url: "10.0.0.2", Db: 1},
+//	}, nil)
+//	defer manager.Close()
+//	session, err := manager.Get("session")
+type Manager struct {
+	logger  i_logger.ILogger
+	options ManagerOptions
+	group   singleflight.Group
+
+	mu        sync.RWMutex
+	configs   map[string]*Configuration
+	instances map[string]*managedInstance
+	draining  map[*RedisType]*time.Timer
+	closed    bool
+}
+
+type managedInstance struct {
+	config *Configuration // 创建时的配置，Reload 时用来判断是否变化
+	redis  *RedisType
+}
+
+func NewManager(logger i_logger.ILogger, configs map[string]*Configuration, options *ManagerOptions) *Manager {
+	m := &Manager{
+		logger:    logger,
+		configs:   copyConfigs(configs),
+		instances: make(map[string]*managedInstance),
+		draining:  make(map[*RedisType]*time.Timer),
+	}
+	if options != nil {
+		m.options = *options
+	}
+	if m.options.Timeout <= 0 {
+		m.options.Timeout = 5 * time.Second
+	}
+	if m.options.DrainTimeout <= 0 {
+		m.options.DrainTimeout = 5 * time.Second
+	}
+	return m
+}
+
+// Connect 会修改 Configuration，这里复制一份，调用方的 map 也可以继续修改
+func copyConfigs(configs map[string]*Configuration) map[string]*Configuration {
+	results := make(map[string]*Configuration, len(configs))
+	for name, config := range configs {
+		c := *config
+		c.Replicas = append([]string(nil), config.Replicas...)
+		results[name] = &c
+	}
+	return results
+}
+
+// 获取指定名字的实例，还没有连接时先连接，同一个名字并发调用只会连接一次
+func (m *Manager) Get(name string) (*RedisType, error) {
+	if instance, err := m.lookup(name); instance != nil || err != nil {
+		return instance, err
+	}
+	result, err, _ := m.group.Do(name, func() (any, error) {
+		for {
+			m.mu.RLock()
+			config, ok := m.configs[name]
+			m.mu.RUnlock()
+			if !ok {
+				return nil, errors.Errorf("<name: %s> not configured", name)
+			}
+			if instance, err := m.lookup(name); instance != nil || err != nil {
+				return instance, err
+			}
+
+			instance := New(m.logger, m.options.Timeout)
+			connectConfig := *config
+			if err := instance.Connect(&connectConfig); err != nil {
+				instance.Close()
+				return nil, errors.Wrapf(err, "<name: %s>", name)
+			}
+
+			m.mu.Lock()
+			if m.closed {
+				m.mu.Unlock()
+				instance.Close()
+				return nil, errors.New("manager closed")
+			}
+			if m.configs[name] != config {
+				// 连接期间配置被 Reload 了，用新配置重新连接
+				m.mu.Unlock()
+				instance.Close()
+				continue
+			}
+			m.instances[name] = &managedInstance{
+				config: config,
+				redis:  instance,
+			}
+			m.mu.Unlock()
+			return instance, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*RedisType), nil
+}
+
+func (m *Manager) lookup(name string) (*RedisType, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return nil, errors.New("manager closed")
+	}
+	if instance, ok := m.instances[name]; ok {
+		return instance.redis, nil
+	}
+	return nil, nil
+}
+
+// 所有配置的名字，按字母排序
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.configs))
+	for name := range m.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 热更新配置。配置没变的实例继续使用；配置变化或被删除的实例从 Manager 中移除，
+// 等待 DrainTimeout 后关闭，下次 Get 时按新配置连接
+func (m *Manager) Reload(configs map[string]*Configuration) {
+	configs = copyConfigs(configs)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return
+	}
+	for name, instance := range m.instances {
+		config, ok := configs[name]
+		if ok && reflect.DeepEqual(instance.config, config) {
+			// 保持指针不变，进行中的 Get 不会被当成过期
+			configs[name] = instance.config
+			continue
+		}
+		delete(m.instances, name)
+		m.drain(name, instance.redis)
+	}
+	m.configs = configs
+}
+
+// 需要持有 m.mu
+func (m *Manager) drain(name string, instance *RedisType) {
+	m.logger.InfoF(`Redis manager: <%s> config changed, close old instance in %s.`, name, m.options.DrainTimeout)
+	m.draining[instance] = time.AfterFunc(m.options.DrainTimeout, func() {
+		m.mu.Lock()
+		_, ok := m.draining[instance]
+		delete(m.draining, instance)
+		m.mu.Unlock()
+		if ok {
+			instance.Close()
+		}
+	})
+}
+
+// 已连接实例的健康状态
+func (m *Manager) Health() map[string]HealthState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	results := make(map[string]HealthState, len(m.instances))
+	for name, instance := range m.instances {
+		results[name] = instance.redis.Health()
+	}
+	return results
+}
+
+// 所有已连接的实例都 Ready 时为 true，用于 readiness 探针
+func (m *Manager) Ready() bool {
+	for _, state := range m.Health() {
+		if state != HealthHealthy && state != HealthDegraded {
+			return false
+		}
+	}
+	return true
+}
+
+// 对所有已连接的实例 ping 一次，用于 liveness 探针，返回第一个失败的实例的错误
+func (m *Manager) Healthy(ctx context.Context) error {
+	m.mu.RLock()
+	instances := make(map[string]*RedisType, len(m.instances))
+	for name, instance := range m.instances {
+		instances[name] = instance.redis
+	}
+	m.mu.RUnlock()
+	names := make([]string, 0, len(instances))
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := instances[name].Healthy(ctx); err != nil {
+			return errors.WithMessagef(err, "<name: %s>", name)
+		}
+	}
+	return nil
+}
+
+// 立即关闭所有实例，包括等待关闭的旧实例。之后 Get 会返回错误
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	instances := make([]*RedisType, 0, len(m.instances)+len(m.draining))
+	for _, instance := range m.instances {
+		instances = append(instances, instance.redis)
+	}
+	for instance, timer := range m.draining {
+		timer.Stop()
+		instances = append(instances, instance)
+	}
+	m.instances = make(map[string]*managedInstance)
+	m.draining = make(map[*RedisType]*time.Timer)
+	m.mu.Unlock()
+	for _, instance := range instances {
+		instance.Close()
+	}
+}
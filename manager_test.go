@@ -0,0 +1,88 @@
+package go_redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+)
+
+func TestManager(t *testing.T) {
+	server1 := miniredis.RunT(t)
+	server2 := miniredis.RunT(t)
+	configs := map[string]*Configuration{
+		"a": {Url: server1.Addr()},
+		"b": {Url: server2.Addr(), Db: 1},
+	}
+	manager := NewManager(&i_logger.DefaultLogger, configs, &ManagerOptions{DrainTimeout: 50 * time.Millisecond})
+	t.Cleanup(manager.Close)
+	go_test_.Equal(t, []string{"a", "b"}, manager.Names())
+	go_test_.Equal(t, 0, server1.CurrentConnectionCount())
+	go_test_.Equal(t, 0, len(manager.Health()))
+
+	// 并发 Get 只连接一次
+	var wg sync.WaitGroup
+	results := make([]*RedisType, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			instance, err := manager.Get("a")
+			go_test_.Equal(t, nil, err)
+			results[i] = instance
+		}(i)
+	}
+	wg.Wait()
+	for _, instance := range results {
+		go_test_.Equal(t, results[0], instance)
+	}
+	go_test_.Equal(t, nil, results[0].String.Set("k", "a", 0))
+	server1.CheckGet(t, "k", "a")
+
+	b, err := manager.Get("b")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, nil, b.String.Set("k", "b", 0))
+	server2.Select(1)
+	server2.CheckGet(t, "k", "b")
+
+	_, err = manager.Get("none")
+	go_test_.NotEqual(t, nil, err)
+
+	go_test_.Equal(t, map[string]HealthState{"a": HealthHealthy, "b": HealthHealthy}, manager.Health())
+	go_test_.Equal(t, true, manager.Ready())
+	go_test_.Equal(t, nil, manager.Healthy(context.Background()))
+
+	// 调用方修改自己的 map 不影响 Manager
+	configs["a"].Url = "127.0.0.1:1"
+
+	// a 不变，b 换到 server1，删除后新增 c
+	manager.Reload(map[string]*Configuration{
+		"a": {Url: server1.Addr()},
+		"b": {Url: server1.Addr()},
+		"c": {Url: server2.Addr()},
+	})
+	a, err := manager.Get("a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, results[0], a)
+	newB, err := manager.Get("b")
+	go_test_.Equal(t, nil, err)
+	go_test_.NotEqual(t, b, newB)
+	value, err := newB.String.Get("k")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "a", value)
+
+	// 旧实例在 DrainTimeout 之前还能用，之后被关闭
+	go_test_.Equal(t, nil, b.String.Set("k2", "b", 0))
+	time.Sleep(150 * time.Millisecond)
+	go_test_.Equal(t, HealthDown, b.Health())
+	go_test_.NotEqual(t, nil, b.String.Set("k2", "b", 0))
+
+	manager.Close()
+	go_test_.Equal(t, HealthDown, a.Health())
+	_, err = manager.Get("a")
+	go_test_.NotEqual(t, nil, err)
+}
@@ -0,0 +1,158 @@
+package go_redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+const MetricsHookName = "metrics"
+
+type MetricsOptions struct {
+	Namespace   string    // 默认 redis
+	Buckets     []float64 // 命令耗时分布，单位秒，默认 100µs 到 1s
+	ConstLabels prometheus.Labels
+}
+
+// Prometheus 指标，实现了 prometheus.Collector，需要调用方自己注册：
+//
+//	prometheus.MustRegister(instance.EnableMetrics(nil))
+//
+// 命令按 type（hash、string、lock 等）和 operation（hash.get 等）分组，直接通过 Db 执行的命令 type 和 operation 为 raw
+type Metrics struct {
+	redis *RedisType
+
+	commandDuration *prometheus.HistogramVec
+	commandErrors   *prometheus.CounterVec
+	lockAcquires    *prometheus.CounterVec
+	pubsubMessages  *prometheus.CounterVec
+
+	poolHits       *prometheus.Desc
+	poolMisses     *prometheus.Desc
+	poolTimeouts   *prometheus.Desc
+	poolTotalConns *prometheus.Desc
+	poolIdleConns  *prometheus.Desc
+	poolStaleConns *prometheus.Desc
+}
+
+// 开启指标统计，重复调用返回同一个 Metrics
+func (t *RedisType) EnableMetrics(options *MetricsOptions) *Metrics {
+	if metrics := t.metrics.Load(); metrics != nil {
+		return metrics
+	}
+	if options == nil {
+		options = &MetricsOptions{}
+	}
+	namespace := options.Namespace
+	if namespace == `` {
+		namespace = "redis"
+	}
+	buckets := options.Buckets
+	if buckets == nil {
+		buckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+	}
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pool", name), help, nil, options.ConstLabels)
+	}
+	m := &Metrics{
+		redis: t,
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "command_duration_seconds",
+			Help:        "Redis command latency.",
+			Buckets:     buckets,
+			ConstLabels: options.ConstLabels,
+		}, []string{"type", "operation", "command"}),
+		commandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "command_errors_total",
+			Help:        "Redis command errors, key not found is not counted.",
+			ConstLabels: options.ConstLabels,
+		}, []string{"type", "operation", "command"}),
+		lockAcquires: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "lock_acquires_total",
+			Help:        "GetLock calls by result (acquired, contended, error).",
+			ConstLabels: options.ConstLabels,
+		}, []string{"result"}),
+		pubsubMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "pubsub_messages_total",
+			Help:        "Pub/sub messages by direction (published, received).",
+			ConstLabels: options.ConstLabels,
+		}, []string{"direction"}),
+		poolHits:       newDesc("hits_total", "Number of times a free connection was found in the pool."),
+		poolMisses:     newDesc("misses_total", "Number of times a free connection was not found in the pool."),
+		poolTimeouts:   newDesc("timeouts_total", "Number of times a wait timeout occurred."),
+		poolTotalConns: newDesc("total_conns", "Number of total connections in the pool."),
+		poolIdleConns:  newDesc("idle_conns", "Number of idle connections in the pool."),
+		poolStaleConns: newDesc("stale_conns_total", "Number of stale connections removed from the pool."),
+	}
+	if !t.metrics.CompareAndSwap(nil, m) {
+		return t.metrics.Load()
+	}
+	t.AddHook(Hook{
+		Name:  MetricsHookName,
+		After: m.observe,
+	})
+	return m
+}
+
+func (m *Metrics) observe(ctx context.Context, event *CommandEvent) {
+	operation := event.Operation
+	if event.Name == "dial" {
+		operation = "conn.dial"
+	}
+	if operation == `` {
+		operation = "raw"
+	}
+	typ, _, _ := strings.Cut(operation, ".")
+	m.commandDuration.WithLabelValues(typ, operation, event.Name).Observe(event.Duration.Seconds())
+	if event.Err != nil && !errors.Is(event.Err, redis.Nil) {
+		m.commandErrors.WithLabelValues(typ, operation, event.Name).Inc()
+	}
+}
+
+func (m *Metrics) observeLock(acquired bool, err error) {
+	switch {
+	case err != nil:
+		m.lockAcquires.WithLabelValues("error").Inc()
+	case acquired:
+		m.lockAcquires.WithLabelValues("acquired").Inc()
+	default:
+		m.lockAcquires.WithLabelValues("contended").Inc()
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.commandDuration.Describe(ch)
+	m.commandErrors.Describe(ch)
+	m.lockAcquires.Describe(ch)
+	m.pubsubMessages.Describe(ch)
+	ch <- m.poolHits
+	ch <- m.poolMisses
+	ch <- m.poolTimeouts
+	ch <- m.poolTotalConns
+	ch <- m.poolIdleConns
+	ch <- m.poolStaleConns
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.commandDuration.Collect(ch)
+	m.commandErrors.Collect(ch)
+	m.lockAcquires.Collect(ch)
+	m.pubsubMessages.Collect(ch)
+	if m.redis.Db == nil {
+		return
+	}
+	stats := m.redis.Db.PoolStats()
+	ch <- prometheus.MustNewConstMetric(m.poolHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(m.poolMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(m.poolTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(m.poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(m.poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(m.poolStaleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
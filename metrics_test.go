@@ -0,0 +1,89 @@
+package go_redis
+
+import (
+	"testing"
+	"time"
+
+	go_test_ "github.com/pefish/go-test"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func gatherMetrics(t *testing.T, registry *prometheus.Registry) map[string][]*dto.Metric {
+	families, err := registry.Gather()
+	go_test_.Equal(t, nil, err)
+	results := make(map[string][]*dto.Metric)
+	for _, family := range families {
+		results[family.GetName()] = family.GetMetric()
+	}
+	return results
+}
+
+func metricLabels(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string)
+	for _, pair := range metric.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return labels
+}
+
+func TestMetrics(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	metrics := instance.EnableMetrics(&MetricsOptions{Namespace: "test"})
+	go_test_.Equal(t, metrics, instance.EnableMetrics(nil))
+	registry := prometheus.NewRegistry()
+	go_test_.Equal(t, nil, registry.Register(metrics))
+
+	go_test_.Equal(t, nil, instance.Hash.Set("h", "f", "v"))
+	_, err := instance.String.Get("none")
+	go_test_.Equal(t, nil, err)
+	_, err = instance.List.Len("h")
+	go_test_.NotEqual(t, nil, err)
+
+	view := instance.WithPrefix("p:")
+	locked, err := view.GetLock("lock", "a", time.Minute)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, locked)
+	locked, err = view.GetLock("lock", "b", time.Minute)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, locked)
+	go_test_.Equal(t, nil, view.ReleaseLock("lock", "a"))
+
+	messages := view.Subscribe("channel")
+	time.Sleep(100 * time.Millisecond)
+	_, err = view.Publish("channel", "hello")
+	go_test_.Equal(t, nil, err)
+	message := <-messages
+	go_test_.Equal(t, "channel", message.Channel)
+
+	families := gatherMetrics(t, registry)
+
+	durations := make(map[string]uint64)
+	for _, metric := range families["test_command_duration_seconds"] {
+		labels := metricLabels(metric)
+		durations[labels["type"]+"|"+labels["operation"]+"|"+labels["command"]] = metric.GetHistogram().GetSampleCount()
+	}
+	go_test_.Equal(t, uint64(1), durations["hash|hash.set|hset"])
+	go_test_.Equal(t, uint64(1), durations["string|string.get|get"])
+	go_test_.Equal(t, uint64(2), durations["lock|lock.acquire|set"])
+	go_test_.Equal(t, uint64(1), durations["lock|lock.release|evalsha"])
+
+	errs := families["test_command_errors_total"]
+	go_test_.Equal(t, 1, len(errs))
+	go_test_.Equal(t, "list.len", metricLabels(errs[0])["operation"])
+
+	locks := make(map[string]float64)
+	for _, metric := range families["test_lock_acquires_total"] {
+		locks[metricLabels(metric)["result"]] = metric.GetCounter().GetValue()
+	}
+	go_test_.Equal(t, map[string]float64{"acquired": 1, "contended": 1}, locks)
+
+	pubsub := make(map[string]float64)
+	for _, metric := range families["test_pubsub_messages_total"] {
+		pubsub[metricLabels(metric)["direction"]] = metric.GetCounter().GetValue()
+	}
+	go_test_.Equal(t, map[string]float64{"published": 1, "received": 1}, pubsub)
+
+	go_test_.Equal(t, 1, len(families["test_pool_total_conns"]))
+	go_test_.Equal(t, true, families["test_pool_total_conns"][0].GetGauge().GetValue() > 0)
+}
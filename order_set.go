@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"strconv"
+	"sync/atomic"
 
 	i_logger "github.com/pefish/go-interface/i-logger"
 	"github.com/pkg/errors"
@@ -11,8 +12,11 @@ import (
 )
 
 type OrderSetType struct {
-	db     *redis.Client
-	logger i_logger.ILogger
+	db      *redis.Client
+	logger  i_logger.ILogger
+	codec   *atomic.Pointer[valueCodec]
+	prefix  keyPrefix
+	baseCtx context.Context
 }
 
 type RangeBy struct {
@@ -22,8 +26,8 @@ type RangeBy struct {
 
 // 向有序集合添加一个或多个成员，或者更新已存在成员的分数
 func (t *OrderSetType) Add(key string, member string, score float64) error {
-	t.logger.DebugF(`Redis zadd. key: %s, member: %s, score: %f`, key, member, score)
-	if err := t.db.ZAdd(context.Background(), key, redis.Z{
+	key = t.prefix.key(key)
+	if err := t.db.ZAdd(t.ctx("add"), key, redis.Z{
 		Score:  score,
 		Member: member,
 	}).Err(); err != nil {
@@ -33,8 +37,8 @@ func (t *OrderSetType) Add(key string, member string, score float64) error {
 }
 
 func (t *OrderSetType) AddBatch(key string, members []redis.Z) error {
-	t.logger.DebugF(`Redis zadd. key: %s, members: ...`, key)
-	if err := t.db.ZAdd(context.Background(), key, members...).Err(); err != nil {
+	key = t.prefix.key(key)
+	if err := t.db.ZAdd(t.ctx("addbatch"), key, members...).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
@@ -42,8 +46,8 @@ func (t *OrderSetType) AddBatch(key string, members []redis.Z) error {
 
 // 移除有序集合中的一个成员
 func (rc *OrderSetType) Remove(key string, member string) (bool, error) {
-	rc.logger.DebugF(`Redis ZRem. key: %s, member: %s`, key, member)
-	result := rc.db.ZRem(context.Background(), key, member)
+	key = rc.prefix.key(key)
+	result := rc.db.ZRem(rc.ctx("remove"), key, member)
 	if err := result.Err(); err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -52,6 +56,7 @@ func (rc *OrderSetType) Remove(key string, member string) (bool, error) {
 
 // 移除有序集合中给定的分数区间的所有成员，math.MaxFloat64 表示 +inf，-1 表示 -inf
 func (rc *OrderSetType) RemRangeByScore(key string, min float64, max float64) error {
+	key = rc.prefix.key(key)
 	minStr := strconv.FormatFloat(min, 'f', -1, 64)
 	if min == -1 {
 		minStr = "-inf"
@@ -60,8 +65,7 @@ func (rc *OrderSetType) RemRangeByScore(key string, min float64, max float64) er
 	if max == math.MaxFloat64 {
 		maxStr = "+inf"
 	}
-	rc.logger.DebugF(`Redis ZRemRangeByScore. key: %s, min: %s, max: %s`, key, minStr, maxStr)
-	if err := rc.db.ZRemRangeByScore(context.Background(), key, minStr, maxStr).Err(); err != nil {
+	if err := rc.db.ZRemRangeByScore(rc.ctx("remrangebyscore"), key, minStr, maxStr).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
@@ -69,6 +73,7 @@ func (rc *OrderSetType) RemRangeByScore(key string, min float64, max float64) er
 
 // 统计分数范围内的元素个数
 func (rc *OrderSetType) Count(key string, min float64, max float64) (int64, error) {
+	key = rc.prefix.key(key)
 	minStr := strconv.FormatFloat(min, 'f', -1, 64)
 	if min == -1 {
 		minStr = "-inf"
@@ -77,8 +82,7 @@ func (rc *OrderSetType) Count(key string, min float64, max float64) (int64, erro
 	if max == math.MaxFloat64 {
 		maxStr = "+inf"
 	}
-	rc.logger.DebugF(`Redis Zcount. key: %s, min: %s, max: %s`, key, minStr, maxStr)
-	r, err := rc.db.ZCount(context.Background(), key, minStr, maxStr).Result()
+	r, err := rc.db.ZCount(rc.ctx("count"), key, minStr, maxStr).Result()
 	if err != nil {
 		return 0, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -87,8 +91,8 @@ func (rc *OrderSetType) Count(key string, min float64, max float64) (int64, erro
 
 // 得到元素总个数
 func (rc *OrderSetType) TotalCount(key string) (int64, error) {
-	rc.logger.DebugF(`Redis ZCard. key: %s`, key)
-	r, err := rc.db.ZCard(context.Background(), key).Result()
+	key = rc.prefix.key(key)
+	r, err := rc.db.ZCard(rc.ctx("totalcount"), key).Result()
 	if err != nil {
 		return 0, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -98,8 +102,8 @@ func (rc *OrderSetType) TotalCount(key string) (int64, error) {
 // 有序集合中对指定成员的分数加上增量 increment
 // 返回值：新分数值
 func (rc *OrderSetType) IncrBy(key string, member string, increment float64) (float64, error) {
-	rc.logger.DebugF(`Redis ZIncrBy. key: %s, member: %s, increment: %f`, key, member, increment)
-	result := rc.db.ZIncrBy(context.Background(), key, increment, member)
+	key = rc.prefix.key(key)
+	result := rc.db.ZIncrBy(rc.ctx("incrby"), key, increment, member)
 	if err := result.Err(); err != nil {
 		return 0, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -109,10 +113,10 @@ func (rc *OrderSetType) IncrBy(key string, member string, increment float64) (fl
 
 // 返回有序集中，指定索引区间内的成员。其中成员的位置按分数值从小到大来排序. start 0, end -1 可取出全部
 func (rc *OrderSetType) Range(key string, start int64, stop int64) ([]string, error) {
-	rc.logger.DebugF(`Redis ZRange. key: %s, start: %d, stop: %d`, key, start, stop)
-	result, err := rc.db.ZRange(context.Background(), key, start, stop).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.db.ZRange(rc.ctx("range"), key, start, stop).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -122,10 +126,10 @@ func (rc *OrderSetType) Range(key string, start int64, stop int64) ([]string, er
 
 // 返回有序集中，指定索引区间内的成员。其中成员的位置按分数值从大到小. start 0, end -1 可取出全部
 func (rc *OrderSetType) RevRange(key string, start int64, stop int64) ([]string, error) {
-	rc.logger.DebugF(`Redis ZRevRange. key: %s, start: %s, stop: %s`, key, start, stop)
-	result, err := rc.db.ZRevRange(context.Background(), key, start, stop).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.db.ZRevRange(rc.ctx("revrange"), key, start, stop).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -135,10 +139,10 @@ func (rc *OrderSetType) RevRange(key string, start int64, stop int64) ([]string,
 
 // 返回有序集中，指定索引区间内的成员以及分数。其中成员的位置按分数值从大到小. start 0, end -1 可取出全部
 func (rc *OrderSetType) RevRangeWithScores(key string, start int64, stop int64) ([]redis.Z, error) {
-	rc.logger.DebugF(`Redis ZRevRangeWithScores. key: %s, start: %d, stop: %d`, key, start, stop)
-	result, err := rc.db.ZRevRangeWithScores(context.Background(), key, start, stop).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.db.ZRevRangeWithScores(rc.ctx("revrangewithscores"), key, start, stop).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -148,6 +152,7 @@ func (rc *OrderSetType) RevRangeWithScores(key string, start int64, stop int64)
 
 // 通过分数返回有序集合指定区间内(左右都包含)的成员, 分数从低到高排序
 func (rc *OrderSetType) RangeByScore(key string, rangeBy RangeBy) ([]string, error) {
+	key = rc.prefix.key(key)
 	minStr := strconv.FormatFloat(rangeBy.Min, 'f', -1, 64)
 	if rangeBy.Min == -1 {
 		minStr = "-inf"
@@ -157,15 +162,14 @@ func (rc *OrderSetType) RangeByScore(key string, rangeBy RangeBy) ([]string, err
 		maxStr = "+inf"
 	}
 
-	rc.logger.DebugF(`Redis ZRangeByScore. key: %s, min: %s, max: %s`, key, minStr, maxStr)
-	result, err := rc.db.ZRangeByScore(context.Background(), key, &redis.ZRangeBy{
+	result, err := rc.db.ZRangeByScore(rc.ctx("rangebyscore"), key, &redis.ZRangeBy{
 		Min:    minStr,
 		Max:    maxStr,
 		Offset: rangeBy.Offset,
 		Count:  rangeBy.Count,
 	}).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -175,6 +179,7 @@ func (rc *OrderSetType) RangeByScore(key string, rangeBy RangeBy) ([]string, err
 
 // 返回有序集中指定分数区间内(左右都包含)的成员, 分数从高到低排序
 func (rc *OrderSetType) RevRangeByScore(key string, rangeBy RangeBy) ([]string, error) {
+	key = rc.prefix.key(key)
 	minStr := strconv.FormatFloat(rangeBy.Min, 'f', -1, 64)
 	if rangeBy.Min == -1 {
 		minStr = "-inf"
@@ -184,15 +189,14 @@ func (rc *OrderSetType) RevRangeByScore(key string, rangeBy RangeBy) ([]string,
 		maxStr = "+inf"
 	}
 
-	rc.logger.DebugF(`Redis ZRevRangeByScore. key: %s, min: %f, max: %f`, key, minStr, maxStr)
-	result, err := rc.db.ZRevRangeByScore(context.Background(), key, &redis.ZRangeBy{
+	result, err := rc.db.ZRevRangeByScore(rc.ctx("revrangebyscore"), key, &redis.ZRangeBy{
 		Min:    minStr,
 		Max:    maxStr,
 		Offset: rangeBy.Offset,
 		Count:  rangeBy.Count,
 	}).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -202,6 +206,7 @@ func (rc *OrderSetType) RevRangeByScore(key string, rangeBy RangeBy) ([]string,
 
 // 返回有序集中指定分数区间内的成员以及分数，分数从高到低排序
 func (rc *OrderSetType) RevRangeByScoreWithScores(key string, rangeBy RangeBy) ([]redis.Z, error) {
+	key = rc.prefix.key(key)
 	minStr := strconv.FormatFloat(rangeBy.Min, 'f', -1, 64)
 	if rangeBy.Min == -1 {
 		minStr = "-inf"
@@ -210,15 +215,14 @@ func (rc *OrderSetType) RevRangeByScoreWithScores(key string, rangeBy RangeBy) (
 	if rangeBy.Max == math.MaxFloat64 {
 		maxStr = "+inf"
 	}
-	rc.logger.DebugF(`Redis ZRevRangeByScoreWithScores. key: %s, min: %f, max: %f`, key, minStr, maxStr)
-	result, err := rc.db.ZRevRangeByScoreWithScores(context.Background(), key, &redis.ZRangeBy{
+	result, err := rc.db.ZRevRangeByScoreWithScores(rc.ctx("revrangebyscorewithscores"), key, &redis.ZRangeBy{
 		Min:    minStr,
 		Max:    maxStr,
 		Offset: rangeBy.Offset,
 		Count:  rangeBy.Count,
 	}).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
+		if errors.Is(err, redis.Nil) {
 			return nil, nil
 		}
 		return nil, errors.Wrapf(err, "<key: %s>", key)
@@ -226,15 +230,25 @@ func (rc *OrderSetType) RevRangeByScoreWithScores(key string, rangeBy RangeBy) (
 	return result, nil
 }
 
-// 返回有序集中，成员的分数值
+// 返回有序集中，成员的分数值。key 或成员不存在时返回 0，需要区分的话用 ScoreOk
 func (rc *OrderSetType) Score(key string, member string) (float64, error) {
-	rc.logger.DebugF(`Redis ZScore. key: %s, member: %s`, key, member)
-	result, err := rc.db.ZScore(context.Background(), key, member).Result()
+	result, _, err := rc.ScoreOk(key, member)
+	return result, err
+}
+
+// 同 Score，found 为 false 表示 key 或成员不存在
+func (rc *OrderSetType) ScoreOk(key string, member string) (result_ float64, found_ bool, err_ error) {
+	key = rc.prefix.key(key)
+	result, err := rc.db.ZScore(rc.ctx("score"), key, member).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
-			return 0, nil
+		if errors.Is(err, redis.Nil) {
+			return 0, false, nil
 		}
-		return 0, errors.Wrapf(err, "<key: %s>", key)
+		return 0, false, errors.Wrapf(err, "<key: %s>", key)
 	}
-	return result, nil
+	return result, true, nil
+}
+
+func (t *OrderSetType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "orderset."+operation)
 }
@@ -0,0 +1,54 @@
+package go_redis
+
+import (
+	"context"
+	"strings"
+)
+
+// key 前缀，所有命令的 key 和频道都会加上它，返回的 key 名会去掉它
+type keyPrefix string
+
+type keyPrefixKey struct{}
+
+func withKeyPrefix(ctx context.Context, prefix keyPrefix) context.Context {
+	return context.WithValue(ctx, keyPrefixKey{}, prefix)
+}
+
+func keyPrefixFromContext(ctx context.Context) keyPrefix {
+	prefix, _ := ctx.Value(keyPrefixKey{}).(keyPrefix)
+	return prefix
+}
+
+func (p keyPrefix) key(key string) string {
+	return string(p) + key
+}
+
+func (p keyPrefix) strip(key string) string {
+	return strings.TrimPrefix(key, string(p))
+}
+
+func (p keyPrefix) strips(keys []string) []string {
+	if p == `` {
+		return keys
+	}
+	for i, key := range keys {
+		keys[i] = p.strip(key)
+	}
+	return keys
+}
+
+// 用于 KEYS、SCAN 的匹配模式，前缀里的通配符需要转义
+func (p keyPrefix) pattern(pattern string) string {
+	if p == `` {
+		return pattern
+	}
+	var b strings.Builder
+	for _, c := range string(p) {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String() + pattern
+}
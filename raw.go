@@ -0,0 +1,158 @@
+package go_redis
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// 下面是二进制安全的泛型读写函数，值可以是 string 或者 []byte（包括以它们为底层类型的自定义类型），任意字节都能原样存取。
+// 写入时不做拷贝；读取 []byte 时会拷贝一份，调用方可以随意修改，读取 string 时不拷贝。
+// key（field、元素）不存在时返回零值，需要区分的话用对应的 Ok 函数
+
+func rawToString[V StringOrBytes](v V) string {
+	// string 和 slice 的头两个字都是数据指针和长度，可以直接按 string 读取
+	return *(*string)(unsafe.Pointer(&v))
+}
+
+func rawFromString[V StringOrBytes](s string) V {
+	if reflect.TypeFor[V]().Kind() == reflect.Slice {
+		// s 可能是客户端缓存中的值，不能把它的内存交给调用方修改
+		b := []byte(s)
+		return *(*V)(unsafe.Pointer(&b))
+	}
+	return *(*V)(unsafe.Pointer(&s))
+}
+
+func rawsToStrings[V StringOrBytes](values []V) []string {
+	results := make([]string, 0, len(values))
+	for _, value := range values {
+		results = append(results, rawToString(value))
+	}
+	return results
+}
+
+func rawsFromStrings[V StringOrBytes](strs []string) []V {
+	if strs == nil {
+		return nil
+	}
+	results := make([]V, 0, len(strs))
+	for _, str := range strs {
+		results = append(results, rawFromString[V](str))
+	}
+	return results
+}
+
+// 设置指定 key 的值
+func SetRaw[V StringOrBytes](t *StringType, key string, value V, expiration time.Duration) error {
+	return t.Set(key, rawToString(value), expiration)
+}
+
+// 获取指定 key 的值
+func GetRaw[V StringOrBytes](t *StringType, key string) (V, error) {
+	result, _, err := GetRawOk[V](t, key)
+	return result, err
+}
+
+func GetRawOk[V StringOrBytes](t *StringType, key string) (result_ V, found_ bool, err_ error) {
+	result, found, err := t.GetOk(key)
+	return rawFromString[V](result), found, err
+}
+
+// 将哈希表 key 中的字段 field 的值设为 value
+func HSetRaw[V StringOrBytes](t *HashType, key, field string, value V) error {
+	return t.Set(key, field, rawToString(value))
+}
+
+// 获取存储在哈希表中指定字段的值
+func HGetRaw[V StringOrBytes](t *HashType, key, field string) (V, error) {
+	result, _, err := HGetRawOk[V](t, key, field)
+	return result, err
+}
+
+func HGetRawOk[V StringOrBytes](t *HashType, key, field string) (result_ V, found_ bool, err_ error) {
+	result, found, err := t.GetOk(key, field)
+	return rawFromString[V](result), found, err
+}
+
+// 获取在哈希表中指定 key 的所有字段和值
+func HGetAllRaw[V StringOrBytes](t *HashType, key string) (map[string]V, error) {
+	result, err := t.GetAll(key)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]V, len(result))
+	for field, value := range result {
+		results[field] = rawFromString[V](value)
+	}
+	return results, nil
+}
+
+// 将一个或多个值插入到列表头部
+func LPushRaw[V StringOrBytes](t *ListType, key string, values ...V) (listLength_ uint64, err_ error) {
+	return t.LPush(key, rawsToStrings(values)...)
+}
+
+// 在列表中添加一个或多个值到列表尾部
+func RPushRaw[V StringOrBytes](t *ListType, key string, values ...V) (listLength_ uint64, err_ error) {
+	return t.RPush(key, rawsToStrings(values)...)
+}
+
+// 移出并获取列表的第一个元素
+func LPopRaw[V StringOrBytes](t *ListType, key string) (V, error) {
+	result, _, err := LPopRawOk[V](t, key)
+	return result, err
+}
+
+func LPopRawOk[V StringOrBytes](t *ListType, key string) (result_ V, found_ bool, err_ error) {
+	result, found, err := t.LPopOk(key)
+	return rawFromString[V](result), found, err
+}
+
+// 移除列表的最后一个元素，返回值为移除的元素
+func RPopRaw[V StringOrBytes](t *ListType, key string) (V, error) {
+	result, _, err := RPopRawOk[V](t, key)
+	return result, err
+}
+
+func RPopRawOk[V StringOrBytes](t *ListType, key string) (result_ V, found_ bool, err_ error) {
+	result, found, err := t.RPopOk(key)
+	return rawFromString[V](result), found, err
+}
+
+// 获取列表指定范围内的元素，key 不存在返回 nil,nil
+func RangeRaw[V StringOrBytes](t *ListType, key string, start int64, stop int64) ([]V, error) {
+	result, err := t.Range(key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	return rawsFromStrings[V](result), nil
+}
+
+// 向集合添加一个或多个成员
+func SAddRaw[V StringOrBytes](t *SetType, key string, members ...V) error {
+	rawMembers := make([]any, 0, len(members))
+	for _, member := range members {
+		rawMembers = append(rawMembers, rawToString(member))
+	}
+	return t.AddBatch(key, rawMembers)
+}
+
+// 移除集合中一个或多个成员
+func SRemRaw[V StringOrBytes](t *SetType, key string, members ...V) error {
+	return t.Remove(key, rawsToStrings(members)...)
+}
+
+// 判断 member 元素是否是集合 key 的成员
+func SIsMemberRaw[V StringOrBytes](t *SetType, key string, member V) (bool, error) {
+	return t.IsMember(key, rawToString(member))
+}
+
+// 返回集合中的所有成员，key 不存在时返回 nil,nil
+func SMembersRaw[V StringOrBytes](t *SetType, key string) ([]V, error) {
+	result, err := t.Members(key)
+	if err != nil {
+		return nil, err
+	}
+	return rawsFromStrings[V](result), nil
+}
@@ -0,0 +1,88 @@
+package go_redis
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+)
+
+func randomPayload(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	// 保证不是合法的 UTF-8，并且包含 \x00 和 \r\n
+	return append(b, 0xff, 0x00, '\r', '\n', 0xc3)
+}
+
+func TestRaw_BinarySafe(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	r := rand.New(rand.NewSource(1))
+
+	payload := randomPayload(r, 256)
+	go_test_.Equal(t, false, utf8.Valid(payload))
+
+	go_test_.Equal(t, nil, SetRaw(instance.String, "test_raw", payload, 0))
+	result, err := GetRaw[[]byte](instance.String, "test_raw")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, bytes.Equal(payload, result))
+	str, err := GetRaw[string](instance.String, "test_raw")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, string(payload), str)
+
+	_, found, err := GetRawOk[[]byte](instance.String, "test_raw_none")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+
+	go_test_.Equal(t, nil, HSetRaw(instance.Hash, "test_raw_hash", "f", payload))
+	result, err = HGetRaw[[]byte](instance.Hash, "test_raw_hash", "f")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, bytes.Equal(payload, result))
+	all, err := HGetAllRaw[[]byte](instance.Hash, "test_raw_hash")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, bytes.Equal(payload, all["f"]))
+
+	payloads := [][]byte{randomPayload(r, 10), randomPayload(r, 0), randomPayload(r, 1000)}
+	_, err = RPushRaw(instance.List, "test_raw_list", payloads...)
+	go_test_.Equal(t, nil, err)
+	results, err := RangeRaw[[]byte](instance.List, "test_raw_list", 0, -1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, payloads, results)
+	result, err = LPopRaw[[]byte](instance.List, "test_raw_list")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, payloads[0], result)
+
+	go_test_.Equal(t, nil, SAddRaw(instance.Set, "test_raw_set", payloads...))
+	isMember, err := SIsMemberRaw(instance.Set, "test_raw_set", payloads[2])
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, isMember)
+	members, err := SMembersRaw[[]byte](instance.Set, "test_raw_set")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, len(members))
+}
+
+func TestRaw_ModifyResult(t *testing.T) {
+	m := miniredis.RunT(t)
+	registerFakeTracking(m)
+	instance := New(&i_logger.DefaultLogger, 5*time.Second)
+	err := instance.Connect(&Configuration{
+		Url:         m.Addr(),
+		ClientCache: &ClientCacheConfig{},
+	})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+
+	go_test_.Equal(t, nil, SetRaw(instance.String, "test_raw_modify", "abc", 0))
+	result, err := GetRaw[[]byte](instance.String, "test_raw_modify")
+	go_test_.Equal(t, nil, err)
+	result[0] = 'x'
+	// 第二次读命中本地缓存，不受上次修改的影响
+	result, err = GetRaw[[]byte](instance.String, "test_raw_modify")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []byte("abc"), result)
+	go_test_.Equal(t, uint64(1), instance.ClientCacheStats().Hits)
+}
@@ -0,0 +1,136 @@
+package go_redis
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const redactedValue = `***`
+
+// 调试日志中值的脱敏方式，key 和命令名不受影响
+type LogRedaction struct {
+	DisableValues bool     // 不输出任何值，只输出命令和 key
+	MaxValueBytes int      // 大于 0 时每个值最多输出这么多字节，超出部分用 ...(n bytes) 表示
+	MaskKeys      []string // key（或发布订阅的频道）匹配这些模式时值输出为 ***，模式语法与 KEYS 命令相同，例如 session:*。WithPrefix 视图上完整的 key 和去掉前缀后的 key 匹配任意一个即可
+}
+
+type logRedactor struct {
+	config   LogRedaction
+	patterns []*regexp.Regexp
+}
+
+// 设置调试日志的脱敏方式，对所有命令生效，包括 pipeline 和 WithPrefix 视图。传 nil 取消脱敏
+func (t *RedisType) SetLogRedaction(redaction *LogRedaction) error {
+	if redaction == nil {
+		t.redactor.Store(nil)
+		return nil
+	}
+	redactor := &logRedactor{
+		config:   *redaction,
+		patterns: make([]*regexp.Regexp, 0, len(redaction.MaskKeys)),
+	}
+	for _, pattern := range redaction.MaskKeys {
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return errors.Wrapf(err, "<pattern: %s>", pattern)
+		}
+		redactor.patterns = append(redactor.patterns, re)
+	}
+	t.redactor.Store(redactor)
+	return nil
+}
+
+// 把命令参数（不包括命令名）格式化成日志，没有设置脱敏时 redactor 为 nil。prefix 是发起命令的视图的前缀
+func (r *logRedactor) args(prefix keyPrefix, name string, args []any) string {
+	if len(args) < 2 {
+		return ``
+	}
+	strs := argStrings(args[1:])
+	if r == nil {
+		return strings.Join(strs, ` `)
+	}
+	// 按下标区分 key 和值，值和 key 字符串相同时也要脱敏
+	indexes := commandKeyIndexes(name, args)
+	if name == "publish" {
+		indexes = []int{1}
+	}
+	isKey := make([]bool, len(args))
+	masked := false
+	for _, i := range indexes {
+		isKey[i] = true
+		if r.match(prefix, strs[i-1]) {
+			masked = true
+		}
+	}
+
+	results := make([]string, 0, len(strs))
+	for i, str := range strs {
+		switch {
+		case isKey[i+1]:
+			results = append(results, str)
+		case r.config.DisableValues:
+			continue
+		case masked:
+			results = append(results, redactedValue)
+		case r.config.MaxValueBytes > 0 && len(str) > r.config.MaxValueBytes:
+			results = append(results, str[:r.config.MaxValueBytes]+`...(`+strconv.Itoa(len(str))+` bytes)`)
+		default:
+			results = append(results, str)
+		}
+	}
+	return strings.Join(results, ` `)
+}
+
+func (r *logRedactor) match(prefix keyPrefix, key string) bool {
+	stripped := ``
+	if prefix != `` && strings.HasPrefix(key, string(prefix)) {
+		stripped = prefix.strip(key)
+	}
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(key) || (stripped != `` && pattern.MatchString(stripped)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 把 KEYS 命令的 glob 模式（* ? [abc] [^a] [a-z] 以及 \ 转义）转换成正则
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unclosed [")
+			}
+			class := pattern[i+1 : i+1+end]
+			b.WriteString(`[`)
+			if strings.HasPrefix(class, `^`) {
+				b.WriteString(`^`)
+				class = class[1:]
+			}
+			b.WriteString(strings.ReplaceAll(strings.ReplaceAll(class, `\`, `\\`), `[`, `\[`))
+			b.WriteString(`]`)
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,146 @@
+package go_redis
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	t_logger "github.com/pefish/go-interface/t-logger"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/redis/go-redis/v9"
+)
+
+type recordLogger struct {
+	i_logger.DefaultLoggerType
+	level t_logger.Level // 为空时是 debug
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordLogger) Level() t_logger.Level {
+	if l.level == `` {
+		return t_logger.Level_DEBUG
+	}
+	return l.level
+}
+
+func (l *recordLogger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.lines)
+}
+
+func (l *recordLogger) Debug(args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(args...))
+}
+
+func (l *recordLogger) last() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lines[len(l.lines)-1]
+}
+
+func TestLogRedaction_Args(t *testing.T) {
+	var r *logRedactor
+	go_test_.Equal(t, "k v EX 60", r.args(``, "set", []any{"set", "k", "v", "EX", 60}))
+
+	newRedactor := func(config LogRedaction) *logRedactor {
+		instance := New(&i_logger.DefaultLogger, 0)
+		go_test_.Equal(t, nil, instance.SetLogRedaction(&config))
+		return instance.redactor.Load()
+	}
+
+	r = newRedactor(LogRedaction{DisableValues: true})
+	go_test_.Equal(t, "k", r.args(``, "set", []any{"set", "k", "v"}))
+	go_test_.Equal(t, "h", r.args(``, "hset", []any{"hset", "h", "f", "secret"}))
+
+	r = newRedactor(LogRedaction{MaxValueBytes: 3})
+	go_test_.Equal(t, "k abc...(6 bytes) 1", r.args(``, "rpush", []any{"rpush", "k", "abcdef", "1"}))
+
+	r = newRedactor(LogRedaction{MaskKeys: []string{"session:*", "token"}})
+	go_test_.Equal(t, "session:1 ***", r.args(``, "set", []any{"set", "session:1", "secret"}))
+	go_test_.Equal(t, "token ***", r.args(``, "publish", []any{"publish", "token", "secret"}))
+	go_test_.Equal(t, "user:1 name", r.args(``, "set", []any{"set", "user:1", "name"}))
+	go_test_.Equal(t, "session:1 ***", r.args(``, "set", []any{"set", "session:1", "session:1"}))
+	go_test_.Equal(t, "session:1 *** session:2 ***", r.args(``, "mset", []any{"mset", "session:1", "session:2", "session:2", "x"}))
+
+	instance := New(&i_logger.DefaultLogger, 0)
+	go_test_.NotEqual(t, nil, instance.SetLogRedaction(&LogRedaction{MaskKeys: []string{"a[b"}}))
+}
+
+func TestLogRedaction_Glob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		match   bool
+	}{
+		{"session:*", "session:a/b", true},
+		{"session:*", "sessions", false},
+		{"h?llo", "hello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"a.b", "axb", false},
+	}
+	for _, c := range cases {
+		re, err := globToRegexp(c.pattern)
+		go_test_.Equal(t, nil, err)
+		go_test_.Equal(t, c.match, re.MatchString(c.key))
+	}
+}
+
+func TestLogRedaction_Logger(t *testing.T) {
+	server := miniredis.RunT(t)
+	recorder := &recordLogger{}
+	instance := New(recorder, 0)
+	err := instance.Connect(&Configuration{Url: server.Addr()})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+
+	go_test_.Equal(t, nil, instance.String.Set("session:1", "token", 0))
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "session:1 token"))
+
+	go_test_.Equal(t, nil, instance.SetLogRedaction(&LogRedaction{MaskKeys: []string{"session:*"}}))
+	view := instance.WithPrefix("app:")
+	go_test_.Equal(t, nil, view.String.Set("1", "token", 0))
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "app:1 token"))
+	go_test_.Equal(t, nil, instance.WithPrefix("session:").Hash.Set("2", "f", "token"))
+	go_test_.Equal(t, false, strings.Contains(recorder.last(), "token"))
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "session:2 *** ***"))
+
+	// 模式不含前缀时，视图上的 key 去掉前缀后匹配
+	go_test_.Equal(t, nil, view.String.Set("session:3", "token", 0))
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "app:session:3 ***"))
+	go_test_.Equal(t, nil, view.WithPrefix("v2:").Hash.Set("session:4", "f", "token"))
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "app:v2:session:4 *** ***"))
+	_, err = view.Db.Pipelined(view.ctx("test"), func(pipe redis.Pipeliner) error {
+		pipe.Set(view.ctx("test"), "app:session:5", "token", 0)
+		return nil
+	})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, strings.Contains(recorder.last(), "app:session:5 ***"))
+}
+
+func TestLogRedaction_LoggerLevel(t *testing.T) {
+	server := miniredis.RunT(t)
+	recorder := &recordLogger{level: t_logger.Level_INFO}
+	instance := New(recorder, 0)
+	err := instance.Connect(&Configuration{Url: server.Addr()})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+
+	go_test_.Equal(t, nil, instance.String.Set("session:1", "token", 0))
+	_, err = instance.Db.Pipelined(instance.baseCtx, func(pipe redis.Pipeliner) error {
+		pipe.Get(instance.baseCtx, "session:1")
+		return nil
+	})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 0, recorder.count())
+}
@@ -3,6 +3,8 @@ package go_redis
 import (
 	"context"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -14,35 +16,93 @@ import (
 // ----------------------------- RedisClass -----------------------------
 
 type RedisType struct {
-	Db       *redis.Client
-	Set      *SetType
-	List     *ListType
-	String   *StringType
-	OrderSet *OrderSetType
-	Hash     *HashType
+	Db          *redis.Client
+	Set         *SetType
+	List        *ListType
+	String      *StringType
+	OrderSet    *OrderSetType
+	Hash        *HashType
+	Bitmap      *BitmapType
+	HyperLogLog *HyperLogLogType
+	Geo         *GeoType
+	JSON        *JSONDocType
+	Search      *SearchType
 
-	logger  i_logger.ILogger
-	timeout time.Duration
+	logger      i_logger.ILogger
+	timeout     time.Duration
+	connMu      *sync.Mutex // Connect 失败时的清理和 Close 互斥
+	clientCache *clientCache
+	codec       *atomic.Pointer[valueCodec]
+	prefix      keyPrefix
+	hooks       *hookChain
+	metrics     *atomic.Pointer[Metrics]
+	redactor    *atomic.Pointer[logRedactor]
+	health      *healthChecker
+	replicas    *replicaRouter
+	scripts     *scriptRegistry
+	baseCtx     context.Context
 }
 
+// SetRaw、GetRaw 等二进制安全函数的值类型
 type StringOrBytes interface {
 	~string | ~[]byte
 }
 
 func New(logger i_logger.ILogger, timeout time.Duration) *RedisType {
+	codec, _ := newValueCodec(nil)
+	codecPtr := &atomic.Pointer[valueCodec]{}
+	codecPtr.Store(codec)
+	redactor := &atomic.Pointer[logRedactor]{}
+	scripts := newScriptRegistry()
+	scripts.register(releaseLockScript)
 	return &RedisType{
 		logger:  logger,
 		timeout: timeout,
+		codec:   codecPtr,
+		hooks: &hookChain{
+			hooks: []Hook{newLoggerHook(logger, redactor)},
+		},
+		metrics:  &atomic.Pointer[Metrics]{},
+		redactor: redactor,
+		connMu:   &sync.Mutex{},
+		health:   newHealthChecker(),
+		scripts:  scripts,
+		baseCtx:  context.Background(),
 	}
 }
 
+// 设置 SetValue、GetValue 等泛型函数使用的编解码器和压缩方式，对所有 WithPrefix 视图生效。
+// 可以和读写并发调用，但切换前写入的值仍按原来的编解码器读取（头字节记录了编解码器）
+func (t *RedisType) SetCodec(config *CodecConfig) error {
+	codec, err := newValueCodec(config)
+	if err != nil {
+		return err
+	}
+	t.codec.Store(codec)
+	return nil
+}
+
 type Configuration struct {
-	Url      string
-	Db       uint64
-	Password string
+	Url           string
+	Db            uint64
+	Password      string
+	ClientCache   *ClientCacheConfig  // 不为 nil 时开启客户端缓存，String.Get 和 Hash.GetAll 优先读本地缓存
+	Health        *HealthConfig       // 不为 nil 时在后台定时 ping，更新 Health 状态
+	Retry         *ConnectRetryConfig // 不为 nil 时连接失败会按退避时间重试
+	Replicas      []string            // 只读副本地址，封装方法的只读命令按 ReplicaPolicy 分发到副本，出错时回退到主库
+	ReplicaPolicy ReplicaPolicy       // 默认 ReplicaRandom
 }
 
 func (t *RedisType) Close() {
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+	t.health.close()
+	if t.replicas != nil {
+		t.replicas.close()
+	}
+	if t.clientCache != nil {
+		t.clientCache.close()
+	}
 	if t.Db != nil {
 		err := t.Db.Close()
 		if err != nil {
@@ -64,43 +124,194 @@ func (t *RedisType) Connect(configuration *Configuration) error {
 		configuration.Url += ":6379"
 	}
 	t.logger.InfoF(`Redis connecting.... url: %s`, configuration.Url)
-	t.Db = redis.NewClient(&redis.Options{
+	options := &redis.Options{
 		Addr:     configuration.Url,
 		Password: password,
 		DB:       int(database),
-	}).WithTimeout(t.timeout)
-	_, err := t.Db.Ping(context.Background()).Result()
-	if err != nil {
-		return errors.Wrap(err, "")
+		OnConnect: func(ctx context.Context, cn *redis.Conn) error {
+			// 加载失败不影响连接，EVALSHA 遇到 NOSCRIPT 时会改用 EVAL
+			if err := t.scripts.preload(ctx, cn); err != nil {
+				t.logger.WarnF(`Redis %s`, err)
+			}
+			return nil
+		},
+	}
+	client := redis.NewClient(options)
+	// 加在原始 client 上，建立连接的事件也能收到
+	client.AddHook(t.hooks)
+	t.connMu.Lock()
+	if len(configuration.Replicas) > 0 {
+		// 加在 t.hooks 之后，日志、指标等钩子包在外层，转发到副本的命令也能收到
+		t.replicas = newReplicaRouter(t.logger, configuration.Replicas, configuration.ReplicaPolicy, options, t.timeout)
+		client.AddHook(t.replicas)
+		t.logger.InfoF(`Redis read replicas enabled. addrs: %s`, strings.Join(t.replicas.addrs, `, `))
 	}
+	t.Db = client.WithTimeout(t.timeout)
+	t.connMu.Unlock()
+	t.health.reset(configuration.Health)
+	t.health.setState(HealthConnecting, nil, 0)
+	if err := t.connectPing(configuration.Retry); err != nil {
+		t.closeFailedConnect(err)
+		return err
+	}
+
+	if configuration.ClientCache != nil {
+		clientCache := newClientCache(t.logger, configuration.ClientCache.MaxEntries)
+		if err := clientCache.start(options, t.hooks); err != nil {
+			t.closeFailedConnect(err)
+			return err
+		}
+		t.connMu.Lock()
+		t.clientCache = clientCache
+		t.connMu.Unlock()
+		t.logger.Info(`Redis client cache enabled.`)
+	}
+	// 全部初始化完成后才算健康，否则 Ready 可能在 Db 被清理后返回 true
+	t.health.setState(HealthHealthy, nil, 0)
 	t.logger.Info(`Redis connect succeed.`)
 
+	t.initTypes()
+	if configuration.Health != nil {
+		t.health.start(t)
+	}
+	return nil
+}
+
+// 连接失败时关闭已经创建的连接池，避免泄漏，也不留下半初始化的 Db
+func (t *RedisType) closeFailedConnect(err error) {
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+	t.health.setState(HealthDown, err, 0)
+	if t.replicas != nil {
+		t.replicas.close()
+		t.replicas = nil
+	}
+	if err := t.Db.Close(); err != nil {
+		t.logger.ErrorF(`Redis close failed. err: %s`, err)
+	}
+	t.Db = nil
+}
+
+func (t *RedisType) connectPing(retry *ConnectRetryConfig) error {
+	for attempt := 1; ; attempt++ {
+		err := t.Db.Ping(withOperation(context.Background(), "conn.ping")).Err()
+		if err == nil {
+			return nil
+		}
+		if retry == nil || (retry.MaxAttempts > 0 && attempt >= retry.MaxAttempts) {
+			return errors.Wrapf(err, "<attempts: %d>", attempt)
+		}
+		backoff := retry.backoff(attempt)
+		t.logger.WarnF(`Redis connect failed, retry in %s. attempt: %d, err: %s`, backoff, attempt, err)
+		if !t.health.wait(backoff) {
+			return errors.Wrap(err, "closed while connecting")
+		}
+	}
+}
+
+func (t *RedisType) initTypes() {
+	baseCtx := t.viewCtx()
 	t.Set = &SetType{
-		db:     t.Db,
-		logger: t.logger,
+		db:      t.Db,
+		logger:  t.logger,
+		codec:   t.codec,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
 	}
 	t.List = &ListType{
-		db:     t.Db,
-		logger: t.logger,
+		db:      t.Db,
+		logger:  t.logger,
+		codec:   t.codec,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
 	}
 	t.String = &StringType{
-		db:     t.Db,
-		logger: t.logger,
+		db:          t.Db,
+		logger:      t.logger,
+		clientCache: t.clientCache,
+		codec:       t.codec,
+		prefix:      t.prefix,
+		baseCtx:     baseCtx,
 	}
 	t.OrderSet = &OrderSetType{
-		db:     t.Db,
-		logger: t.logger,
+		db:      t.Db,
+		logger:  t.logger,
+		codec:   t.codec,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
+	}
+	t.Bitmap = &BitmapType{
+		db:      t.Db,
+		logger:  t.logger,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
+	}
+	t.HyperLogLog = &HyperLogLogType{
+		db:      t.Db,
+		logger:  t.logger,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
+	}
+	t.Geo = &GeoType{
+		db:      t.Db,
+		logger:  t.logger,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
+	}
+	t.JSON = &JSONDocType{
+		db:      t.Db,
+		logger:  t.logger,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
+	}
+	t.Search = &SearchType{
+		db:      t.Db,
+		logger:  t.logger,
+		prefix:  t.prefix,
+		baseCtx: baseCtx,
 	}
 	t.Hash = &HashType{
-		db:     t.Db,
-		logger: t.logger,
+		db:          t.Db,
+		logger:      t.logger,
+		clientCache: t.clientCache,
+		codec:       t.codec,
+		prefix:      t.prefix,
+		baseCtx:     baseCtx,
 	}
-	return nil
+}
+
+// 返回一个共享连接的视图，视图上所有命令的 key（包括锁、发布订阅的频道）都会自动加上 prefix，
+// Keys、Scan 返回的 key 会去掉 prefix。可以嵌套，例如 WithPrefix("a:").WithPrefix("b:") 的前缀是 a:b:。
+// 必须在 Connect 之后调用，连接由原实例负责关闭
+func (t *RedisType) WithPrefix(prefix string) *RedisType {
+	view := *t
+	view.prefix = t.prefix + keyPrefix(prefix)
+	view.initTypes()
+	return &view
+}
+
+// 返回一个共享连接的视图，视图上的所有命令都使用 ctx，用于超时、取消和链路追踪。
+// 必须在 Connect 之后调用，连接由原实例负责关闭
+//
+//	err := instance.WithContext(ctx).String.Set("key", "value", 0)
+func (t *RedisType) WithContext(ctx context.Context) *RedisType {
+	view := *t
+	view.baseCtx = ctx
+	view.initTypes()
+	return &view
+}
+
+// 客户端缓存的命中统计，没有开启时返回零值
+func (rc *RedisType) ClientCacheStats() ClientCacheStats {
+	if rc.clientCache == nil {
+		return ClientCacheStats{}
+	}
+	return rc.clientCache.getStats()
 }
 
 func (rc *RedisType) Del(key string) (bool, error) {
-	rc.logger.DebugF(`Redis del. key: %s`, key)
-	result := rc.Db.Del(context.Background(), key)
+	key = rc.prefix.key(key)
+	result := rc.Db.Del(rc.ctx("key.del"), key)
 	if result.Err() != nil {
 		return false, errors.Wrapf(result.Err(), "<key: %s>", key)
 	}
@@ -108,8 +319,8 @@ func (rc *RedisType) Del(key string) (bool, error) {
 }
 
 func (rc *RedisType) Exists(key string) (bool, error) {
-	rc.logger.DebugF(`Redis exists. key: %s`, key)
-	result, err := rc.Db.Exists(context.Background(), key).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.Db.Exists(rc.ctx("key.exists"), key).Result()
 	if err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -117,43 +328,84 @@ func (rc *RedisType) Exists(key string) (bool, error) {
 }
 
 func (rc *RedisType) Keys(pattern string) ([]string, error) {
-	rc.logger.DebugF(`Redis keys. pattern: %s`, pattern)
-	results, err := rc.Db.Keys(context.Background(), pattern).Result()
+	pattern = rc.prefix.pattern(pattern)
+	results, err := rc.Db.Keys(rc.ctx("key.keys"), pattern).Result()
 	if err != nil {
 		return nil, errors.Wrapf(err, "<pattern: %s>", pattern)
 	}
-	return results, nil
+	return rc.prefix.strips(results), nil
+}
+
+// 增量遍历匹配的 key，cursor 从 0 开始，返回的 nextCursor 为 0 表示遍历结束
+func (rc *RedisType) Scan(cursor uint64, match string, count int64) (keys_ []string, nextCursor_ uint64, err_ error) {
+	match = rc.prefix.pattern(match)
+	keys, nextCursor, err := rc.Db.Scan(rc.ctx("key.scan"), cursor, match, count).Result()
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "<match: %s>", match)
+	}
+	return rc.prefix.strips(keys), nextCursor, nil
 }
 
 func (rc *RedisType) Publish(channel string, message string) (receivedSubscriberCount_ uint64, err_ error) {
-	rc.logger.DebugF(`Redis publish. channel: %s, message: %s`, channel, message)
-	result, err := rc.Db.Publish(context.Background(), channel, message).Result()
+	channel = rc.prefix.key(channel)
+	result, err := rc.Db.Publish(rc.ctx("pubsub.publish"), channel, message).Result()
 	if err != nil {
 		return 0, errors.Wrapf(err, "<channel: %s>", channel)
 	}
+	if metrics := rc.metrics.Load(); metrics != nil {
+		metrics.pubsubMessages.WithLabelValues("published").Inc()
+	}
 	return uint64(result), nil
 }
 
 func (rc *RedisType) Subscribe(channel string) <-chan *redis.Message {
-	rc.logger.DebugF(`Redis subscribe. channel: %s`, channel)
-	return rc.Db.Subscribe(context.Background(), channel).Channel()
+	channel = rc.prefix.key(channel)
+	messages := rc.Db.Subscribe(rc.ctx("pubsub.subscribe"), channel).Channel()
+	metrics := rc.metrics.Load()
+	if rc.prefix == `` && metrics == nil {
+		return messages
+	}
+	// 统计收到的消息，去掉消息中频道名的前缀
+	results := make(chan *redis.Message, cap(messages))
+	go func() {
+		defer close(results)
+		for message := range messages {
+			if metrics != nil {
+				metrics.pubsubMessages.WithLabelValues("received").Inc()
+			}
+			if rc.prefix != `` {
+				stripped := *message
+				stripped.Channel = rc.prefix.strip(message.Channel)
+				message = &stripped
+			}
+			results <- message
+		}
+	}()
+	return results
 }
 
 func (rc *RedisType) Expire(key string, expiration time.Duration) error {
-	rc.logger.DebugF(`Redis expire. key: %s, expiration: %v`, key, expiration)
-	if err := rc.Db.Expire(context.Background(), key, expiration).Err(); err != nil {
+	key = rc.prefix.key(key)
+	if err := rc.Db.Expire(rc.ctx("key.expire"), key, expiration).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
 }
 
 func (rc *RedisType) GetLock(key string, value string, expiration time.Duration) (bool, error) {
-	result, err := rc.String.SetNX(key, value, expiration)
+	key = rc.prefix.key(key)
+	// 续锁协程一直用这个连接，不再读 rc.Db
+	db := rc.Db
+	result, err := db.SetNX(rc.ctx("lock.acquire"), key, value, expiration).Result()
+	if metrics := rc.metrics.Load(); metrics != nil {
+		metrics.observeLock(result, err)
+	}
 	if err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
 	if result {
-		// 自动续锁
+		// 自动续锁，不受调用方 ctx 取消的影响，读主库判断锁是否还是自己的
+		ctx := withOperation(ForcePrimary(context.WithoutCancel(rc.viewCtx())), "lock.renew")
 		go func() {
 			timerInterval := expiration / 2
 			d := time.Duration(timerInterval)
@@ -162,9 +414,9 @@ func (rc *RedisType) GetLock(key string, value string, expiration time.Duration)
 
 			for {
 				<-t.C
-				v, _ := rc.String.Get(key)
+				v, _ := db.Get(ctx, key).Result()
 				if v == value {
-					err := rc.Expire(key, expiration)
+					err := db.Expire(ctx, key, expiration).Err()
 					if err != nil {
 						break
 					}
@@ -177,16 +429,28 @@ func (rc *RedisType) GetLock(key string, value string, expiration time.Duration)
 	return result, nil
 }
 
+var releaseLockScript = NewScript[int64]("lock.release",
+	`if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('del', KEYS[1]) else return 0 end`, 1, 1, nil)
+
 func (rc *RedisType) ReleaseLock(key string, value string) error {
-	script := `if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('del', KEYS[1]) else return 0 end`
-	rc.logger.DebugF(`Redis eval. script: %s`, script)
-	result := rc.Db.Eval(context.Background(), script, []string{key}, []string{value})
-	if err := result.Err(); err != nil {
-		return errors.Wrapf(err, "<key: %s>", key)
+	if _, _, err := releaseLockScript.run(rc.ctx("lock.release"), rc, []string{key}, value); err != nil {
+		return err
 	}
 	return nil
 }
 
+func (t *RedisType) ctx(operation string) context.Context {
+	return withOperation(t.viewCtx(), operation)
+}
+
+// 视图上发起的命令都带着视图的前缀，日志脱敏时用它去掉前缀再匹配
+func (t *RedisType) viewCtx() context.Context {
+	if t.prefix == `` {
+		return t.baseCtx
+	}
+	return withKeyPrefix(t.baseCtx, t.prefix)
+}
+
 // BytesToString converts byte slice to string.
 func BytesToString(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
@@ -2,9 +2,12 @@ package go_redis
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
 	i_logger "github.com/pefish/go-interface/i-logger"
 	go_test_ "github.com/pefish/go-test"
@@ -20,6 +23,17 @@ func init() {
 	})
 }
 
+func newMiniRedisInstance(t *testing.T) (*RedisType, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	instance := New(&i_logger.DefaultLogger, 5*time.Second)
+	err := instance.Connect(&Configuration{
+		Url: server.Addr(),
+	})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+	return instance, server
+}
+
 func TestRedisClass_ConnectWithConfiguration(t *testing.T) {
 	RedisInstance.Close()
 }
@@ -82,3 +96,177 @@ func Test__ListClass_ListAll(t *testing.T) {
 	go_test_.Equal(t, nil, err)
 	fmt.Println(result1)
 }
+
+func TestRedisClass_GetOk(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	_, found, err := instance.String.GetOk("test_ok")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	go_test_.Equal(t, nil, instance.String.Set("test_ok", "", 0))
+	result, found, err := instance.String.GetOk("test_ok")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, found)
+	go_test_.Equal(t, "", result)
+
+	_, found, err = instance.Hash.GetUint64Ok("test_ok_hash", "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	go_test_.Equal(t, nil, instance.Hash.SetUint64("test_ok_hash", "a", 0))
+	_, found, err = instance.Hash.GetUint64Ok("test_ok_hash", "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, found)
+
+	_, found, err = instance.List.LPopOk("test_ok_list")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+
+	_, found, err = instance.OrderSet.ScoreOk("test_ok_zset", "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	go_test_.Equal(t, nil, instance.OrderSet.Add("test_ok_zset", "a", 0))
+	_, found, err = instance.OrderSet.ScoreOk("test_ok_zset", "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, found)
+}
+
+func TestHashClass_Struct(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	type Profile struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Name      string        `redis:"name"`
+		Age       uint8         `redis:"age,omitempty"`
+		Admin     bool          `redis:"admin"`
+		Score     *float64      `redis:"score"`
+		Timeout   time.Duration `redis:"timeout"`
+		CreatedAt time.Time     `redis:"created_at"`
+		Profile   Profile       `redis:"profile"`
+		Tags      []string      `redis:"tags,omitempty"`
+		Ignored   string
+	}
+	score := 9.5
+	src := User{
+		Name:      "pefish",
+		Admin:     true,
+		Score:     &score,
+		Timeout:   time.Minute,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Profile:   Profile{City: "sz"},
+		Ignored:   "x",
+	}
+	go_test_.Equal(t, nil, instance.Hash.SetStruct("test_hash_struct", &src))
+
+	all, err := instance.Hash.GetAll("test_hash_struct")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 6, len(all))
+	go_test_.Equal(t, `{"city":"sz"}`, all["profile"])
+
+	var dst User
+	go_test_.Equal(t, nil, instance.Hash.GetStruct("test_hash_struct", &dst))
+	src.Ignored = ""
+	go_test_.Equal(t, src, dst)
+
+	// 只读取需要的字段
+	var partial struct {
+		Name string `redis:"name"`
+	}
+	go_test_.Equal(t, nil, instance.Hash.GetStruct("test_hash_struct", &partial))
+	go_test_.Equal(t, "pefish", partial.Name)
+
+	go_test_.Equal(t, nil, instance.Hash.Set("test_hash_struct", "age", "old"))
+	err = instance.Hash.GetStruct("test_hash_struct", &dst)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_hash_struct, field: age> string <old> to uint8 failed."))
+}
+
+func TestRedisClass_WithPrefix(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	view := instance.WithPrefix("svc:")
+
+	go_test_.Equal(t, nil, view.String.Set("a", "1", 0))
+	go_test_.Equal(t, nil, view.Hash.Set("b", "f", "2"))
+	server.CheckGet(t, "svc:a", "1")
+	go_test_.Equal(t, true, server.Exists("svc:b"))
+
+	keys, err := view.Keys("*")
+	go_test_.Equal(t, nil, err)
+	sort.Strings(keys)
+	go_test_.Equal(t, []string{"a", "b"}, keys)
+	keys, _, err = view.WithPrefix("x:").Scan(0, "*", 10)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 0, len(keys))
+
+	exists, err := view.Exists("a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, exists)
+
+	messages := view.Subscribe("ch")
+	time.Sleep(50 * time.Millisecond)
+	_, err = instance.Publish("svc:ch", "hello")
+	go_test_.Equal(t, nil, err)
+	message := <-messages
+	go_test_.Equal(t, "ch", message.Channel)
+	go_test_.Equal(t, "hello", message.Payload)
+}
+
+func TestRedisClass_ConnectFailed(t *testing.T) {
+	server := miniredis.NewMiniRedis()
+	go_test_.Equal(t, nil, server.Start())
+	addr := server.Addr()
+	server.Close()
+
+	instance := New(&i_logger.DefaultLogger, time.Second)
+	err := instance.Connect(&Configuration{
+		Url:      addr,
+		Replicas: []string{addr},
+	})
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, true, instance.Db == nil)
+	go_test_.Equal(t, true, instance.replicas == nil)
+	instance.Close()
+
+	// 客户端缓存启动失败（miniredis 不支持 CLIENT TRACKING）
+	running := miniredis.RunT(t)
+	instance = New(&i_logger.DefaultLogger, time.Second)
+	err = instance.Connect(&Configuration{
+		Url:         running.Addr(),
+		Replicas:    []string{running.Addr()},
+		ClientCache: &ClientCacheConfig{},
+	})
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, true, instance.Db == nil)
+	go_test_.Equal(t, true, instance.replicas == nil)
+	go_test_.Equal(t, true, instance.clientCache == nil)
+	go_test_.Equal(t, HealthDown, instance.Health())
+	go_test_.Equal(t, false, instance.Ready())
+	instance.Close()
+	// 服务端感知连接关闭有延迟
+	deadline := time.Now().Add(time.Second)
+	for running.CurrentConnectionCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	go_test_.Equal(t, 0, running.CurrentConnectionCount())
+}
+
+func TestRedisClass_CloseWhileConnecting(t *testing.T) {
+	server := miniredis.NewMiniRedis()
+	go_test_.Equal(t, nil, server.Start())
+	addr := server.Addr()
+	server.Close()
+
+	instance := New(&i_logger.DefaultLogger, time.Second)
+	result := make(chan error, 1)
+	go func() {
+		result <- instance.Connect(&Configuration{
+			Url:      addr,
+			Replicas: []string{addr},
+			Retry:    &ConnectRetryConfig{InitialBackoff: 10 * time.Millisecond},
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	instance.Close()
+	go_test_.NotEqual(t, nil, <-result)
+	go_test_.Equal(t, false, instance.Ready())
+}
@@ -0,0 +1,166 @@
+//go:build ignore
+
+// 根据 ../interface.go 里的接口生成 mock.go，接口变化后运行 go generate ./redismock
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+var interfaces = []string{"IRedis", "IString", "IHash", "IList", "ISet", "IOrderSet", "IBitmap", "IHyperLogLog", "IGeo", "IJSONDoc", "ISearch"}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../interface.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	specs := make(map[string]*ast.InterfaceType)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+			specs[spec.Name.Name] = iface
+		}
+		return false
+	})
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage redismock\n\n")
+	b.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n\t\"time\"\n\n\tgo_redis \"github.com/pefish/go-redis\"\n\t\"github.com/redis/go-redis/v9\"\n)\n\n")
+	for _, name := range interfaces {
+		iface, ok := specs[name]
+		if !ok {
+			log.Fatalf("interface %s not found", name)
+		}
+		mock := "Mock" + strings.TrimPrefix(name, "I")
+		fmt.Fprintf(&b, "// %s 实现 go_redis.%s\ntype %s struct {\n\tMock\n}\n\n", mock, name, mock)
+		fmt.Fprintf(&b, "var _ go_redis.%s = (*%s)(nil)\n\n", name, mock)
+		for _, method := range iface.Methods.List {
+			writeMethod(&b, fset, mock, method)
+		}
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, b.String())
+	}
+	if err := os.WriteFile("mock.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMethod(b *bytes.Buffer, fset *token.FileSet, mock string, method *ast.Field) {
+	name := method.Names[0].Name
+	fn := method.Type.(*ast.FuncType)
+
+	params := make([]string, 0)
+	args := make([]string, 0)
+	i := 0
+	for _, field := range fn.Params.List {
+		typ := typeString(fset, field.Type)
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", i))}
+		}
+		for _, n := range names {
+			params = append(params, n.Name+" "+typ)
+			args = append(args, n.Name)
+			i++
+		}
+	}
+
+	results := make([]string, 0)
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			for j := 0; j < count; j++ {
+				results = append(results, typeString(fset, field.Type))
+			}
+		}
+	}
+
+	fmt.Fprintf(b, "func (m *%s) %s(%s)", mock, name, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		fmt.Fprintf(b, " %s", results[0])
+	default:
+		fmt.Fprintf(b, " (%s)", strings.Join(results, ", "))
+	}
+	b.WriteString(" {\n")
+	call := fmt.Sprintf("m.called(%q", name)
+	if len(args) > 0 {
+		call += ", " + strings.Join(args, ", ")
+	}
+	call += ")"
+	if len(results) == 0 {
+		fmt.Fprintf(b, "\t%s\n}\n\n", call)
+		return
+	}
+	fmt.Fprintf(b, "\treturns := %s\n\treturn ", call)
+	for j, result := range results {
+		if j > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "returnValue[%s](returns, %d)", result, j)
+	}
+	b.WriteString("\n}\n\n")
+}
+
+// 把类型表达式转成字符串，go_redis 包里的类型加上包名
+func typeString(fset *token.FileSet, expr ast.Expr) string {
+	expr = qualify(expr)
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, expr); err != nil {
+		log.Fatal(err)
+	}
+	return b.String()
+}
+
+func qualify(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(e.Name) {
+			return &ast.SelectorExpr{X: ast.NewIdent("go_redis"), Sel: ast.NewIdent(e.Name)}
+		}
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualify(e.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualify(e.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualify(e.Key), Value: qualify(e.Value)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: qualify(e.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualify(e.Elt)}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: qualifyFields(e.Params), Results: qualifyFields(e.Results)}
+	}
+	return expr
+}
+
+func qualifyFields(fields *ast.FieldList) *ast.FieldList {
+	if fields == nil {
+		return nil
+	}
+	result := &ast.FieldList{}
+	for _, field := range fields.List {
+		result.List = append(result.List, &ast.Field{Names: field.Names, Type: qualify(field.Type)})
+	}
+	return result
+}
@@ -0,0 +1,790 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package redismock
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	go_redis "github.com/pefish/go-redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// MockRedis 实现 go_redis.IRedis
+type MockRedis struct {
+	Mock
+}
+
+var _ go_redis.IRedis = (*MockRedis)(nil)
+
+func (m *MockRedis) Connect(configuration *go_redis.Configuration) error {
+	returns := m.called("Connect", configuration)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) Close() {
+	m.called("Close")
+}
+
+func (m *MockRedis) SetCodec(config *go_redis.CodecConfig) error {
+	returns := m.called("SetCodec", config)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) AddHook(hook go_redis.Hook) {
+	m.called("AddHook", hook)
+}
+
+func (m *MockRedis) RemoveHook(name string) bool {
+	returns := m.called("RemoveHook", name)
+	return returnValue[bool](returns, 0)
+}
+
+func (m *MockRedis) EnableMetrics(options *go_redis.MetricsOptions) *go_redis.Metrics {
+	returns := m.called("EnableMetrics", options)
+	return returnValue[*go_redis.Metrics](returns, 0)
+}
+
+func (m *MockRedis) EnableTracing(options *go_redis.TracingOptions) {
+	m.called("EnableTracing", options)
+}
+
+func (m *MockRedis) SetLogRedaction(redaction *go_redis.LogRedaction) error {
+	returns := m.called("SetLogRedaction", redaction)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) RegisterScripts(scripts ...go_redis.ScriptSource) error {
+	returns := m.called("RegisterScripts", scripts)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) LoadFunctionLibrary(library *go_redis.FunctionLibrary) (bool, error) {
+	returns := m.called("LoadFunctionLibrary", library)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) ListFunctionLibraries(pattern string) ([]go_redis.FunctionLibraryInfo, error) {
+	returns := m.called("ListFunctionLibraries", pattern)
+	return returnValue[[]go_redis.FunctionLibraryInfo](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) DeleteFunctionLibrary(name string) (bool, error) {
+	returns := m.called("DeleteFunctionLibrary", name)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) ClientCacheStats() go_redis.ClientCacheStats {
+	returns := m.called("ClientCacheStats")
+	return returnValue[go_redis.ClientCacheStats](returns, 0)
+}
+
+func (m *MockRedis) Health() go_redis.HealthState {
+	returns := m.called("Health")
+	return returnValue[go_redis.HealthState](returns, 0)
+}
+
+func (m *MockRedis) Ready() bool {
+	returns := m.called("Ready")
+	return returnValue[bool](returns, 0)
+}
+
+func (m *MockRedis) Healthy(ctx context.Context) error {
+	returns := m.called("Healthy", ctx)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) OnHealthChange(fn func(event go_redis.HealthEvent)) {
+	m.called("OnHealthChange", fn)
+}
+
+func (m *MockRedis) Strings() go_redis.IString {
+	returns := m.called("Strings")
+	return returnValue[go_redis.IString](returns, 0)
+}
+
+func (m *MockRedis) Hashes() go_redis.IHash {
+	returns := m.called("Hashes")
+	return returnValue[go_redis.IHash](returns, 0)
+}
+
+func (m *MockRedis) Lists() go_redis.IList {
+	returns := m.called("Lists")
+	return returnValue[go_redis.IList](returns, 0)
+}
+
+func (m *MockRedis) Sets() go_redis.ISet {
+	returns := m.called("Sets")
+	return returnValue[go_redis.ISet](returns, 0)
+}
+
+func (m *MockRedis) OrderSets() go_redis.IOrderSet {
+	returns := m.called("OrderSets")
+	return returnValue[go_redis.IOrderSet](returns, 0)
+}
+
+func (m *MockRedis) Bitmaps() go_redis.IBitmap {
+	returns := m.called("Bitmaps")
+	return returnValue[go_redis.IBitmap](returns, 0)
+}
+
+func (m *MockRedis) HyperLogLogs() go_redis.IHyperLogLog {
+	returns := m.called("HyperLogLogs")
+	return returnValue[go_redis.IHyperLogLog](returns, 0)
+}
+
+func (m *MockRedis) Geos() go_redis.IGeo {
+	returns := m.called("Geos")
+	return returnValue[go_redis.IGeo](returns, 0)
+}
+
+func (m *MockRedis) JSONDocs() go_redis.IJSONDoc {
+	returns := m.called("JSONDocs")
+	return returnValue[go_redis.IJSONDoc](returns, 0)
+}
+
+func (m *MockRedis) Searches() go_redis.ISearch {
+	returns := m.called("Searches")
+	return returnValue[go_redis.ISearch](returns, 0)
+}
+
+func (m *MockRedis) Del(key string) (bool, error) {
+	returns := m.called("Del", key)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) Exists(key string) (bool, error) {
+	returns := m.called("Exists", key)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) Keys(pattern string) ([]string, error) {
+	returns := m.called("Keys", pattern)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	returns := m.called("Scan", cursor, match, count)
+	return returnValue[[]string](returns, 0), returnValue[uint64](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockRedis) Expire(key string, expiration time.Duration) error {
+	returns := m.called("Expire", key, expiration)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockRedis) Publish(channel string, message string) (uint64, error) {
+	returns := m.called("Publish", channel, message)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) Subscribe(channel string) <-chan *redis.Message {
+	returns := m.called("Subscribe", channel)
+	return returnValue[<-chan *redis.Message](returns, 0)
+}
+
+func (m *MockRedis) GetLock(key string, value string, expiration time.Duration) (bool, error) {
+	returns := m.called("GetLock", key, value, expiration)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockRedis) ReleaseLock(key string, value string) error {
+	returns := m.called("ReleaseLock", key, value)
+	return returnValue[error](returns, 0)
+}
+
+// MockString 实现 go_redis.IString
+type MockString struct {
+	Mock
+}
+
+var _ go_redis.IString = (*MockString)(nil)
+
+func (m *MockString) Set(key string, value string, expiration time.Duration) error {
+	returns := m.called("Set", key, value, expiration)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockString) SetUint64(key string, value uint64, expiration time.Duration) error {
+	returns := m.called("SetUint64", key, value, expiration)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockString) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	returns := m.called("SetNX", key, value, expiration)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockString) Get(key string) (string, error) {
+	returns := m.called("Get", key)
+	return returnValue[string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockString) GetOk(key string) (string, bool, error) {
+	returns := m.called("GetOk", key)
+	return returnValue[string](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockString) GetUint64(key string) (uint64, error) {
+	returns := m.called("GetUint64", key)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockString) GetUint64Ok(key string) (uint64, bool, error) {
+	returns := m.called("GetUint64Ok", key)
+	return returnValue[uint64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockString) GetFloat64(key string) (float64, error) {
+	returns := m.called("GetFloat64", key)
+	return returnValue[float64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockString) GetFloat64Ok(key string) (float64, bool, error) {
+	returns := m.called("GetFloat64Ok", key)
+	return returnValue[float64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockString) IncrBy(key string, increment int64) (int64, error) {
+	returns := m.called("IncrBy", key, increment)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+// MockHash 实现 go_redis.IHash
+type MockHash struct {
+	Mock
+}
+
+var _ go_redis.IHash = (*MockHash)(nil)
+
+func (m *MockHash) Exists(key string, field string) (bool, error) {
+	returns := m.called("Exists", key, field)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Get(key string, field string) (string, error) {
+	returns := m.called("Get", key, field)
+	return returnValue[string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) GetOk(key string, field string) (string, bool, error) {
+	returns := m.called("GetOk", key, field)
+	return returnValue[string](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockHash) GetBatch(key string, fields []string) ([]any, error) {
+	returns := m.called("GetBatch", key, fields)
+	return returnValue[[]any](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) RandomGetFields(key string, count int) ([]string, error) {
+	returns := m.called("RandomGetFields", key, count)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) GetUint64(key string, field string) (uint64, error) {
+	returns := m.called("GetUint64", key, field)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) GetUint64Ok(key string, field string) (uint64, bool, error) {
+	returns := m.called("GetUint64Ok", key, field)
+	return returnValue[uint64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockHash) GetFloat64(key string, field string) (float64, error) {
+	returns := m.called("GetFloat64", key, field)
+	return returnValue[float64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) GetFloat64Ok(key string, field string) (float64, bool, error) {
+	returns := m.called("GetFloat64Ok", key, field)
+	return returnValue[float64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockHash) GetAll(key string) (map[string]string, error) {
+	returns := m.called("GetAll", key)
+	return returnValue[map[string]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Set(key string, field string, value string) error {
+	returns := m.called("Set", key, field, value)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) SetBatch(key string, fieldValues map[string]any) error {
+	returns := m.called("SetBatch", key, fieldValues)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) SetUint64(key string, field string, value uint64) error {
+	returns := m.called("SetUint64", key, field, value)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) SetNX(key string, field string, value string) (bool, error) {
+	returns := m.called("SetNX", key, field, value)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Del(key string, field string) (bool, error) {
+	returns := m.called("Del", key, field)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) DelBatch(key string, fields []string) (int64, error) {
+	returns := m.called("DelBatch", key, fields)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Len(key string) (int64, error) {
+	returns := m.called("Len", key)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Fields(key string) ([]string, error) {
+	returns := m.called("Fields", key)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) Values(key string) ([]string, error) {
+	returns := m.called("Values", key)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) IncrBy(key string, field string, increment int64) (int64, error) {
+	returns := m.called("IncrBy", key, field, increment)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) SetStruct(key string, src any) error {
+	returns := m.called("SetStruct", key, src)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) GetStruct(key string, dst any) error {
+	returns := m.called("GetStruct", key, dst)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) SetVector(key string, field string, vector []float32) error {
+	returns := m.called("SetVector", key, field, vector)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockHash) GetVector(key string, field string) ([]float32, error) {
+	returns := m.called("GetVector", key, field)
+	return returnValue[[]float32](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHash) GetVectorOk(key string, field string) ([]float32, bool, error) {
+	returns := m.called("GetVectorOk", key, field)
+	return returnValue[[]float32](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+// MockList 实现 go_redis.IList
+type MockList struct {
+	Mock
+}
+
+var _ go_redis.IList = (*MockList)(nil)
+
+func (m *MockList) LPush(key string, values ...string) (uint64, error) {
+	returns := m.called("LPush", key, values)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) LPushUint64(key string, values ...uint64) (uint64, error) {
+	returns := m.called("LPushUint64", key, values)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) RPush(key string, values ...string) (uint64, error) {
+	returns := m.called("RPush", key, values)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) RPushUint64(key string, values ...uint64) (uint64, error) {
+	returns := m.called("RPushUint64", key, values)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) LPop(key string) (string, error) {
+	returns := m.called("LPop", key)
+	return returnValue[string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) LPopOk(key string) (string, bool, error) {
+	returns := m.called("LPopOk", key)
+	return returnValue[string](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) LPopUint64(key string) (uint64, error) {
+	returns := m.called("LPopUint64", key)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) LPopUint64Ok(key string) (uint64, bool, error) {
+	returns := m.called("LPopUint64Ok", key)
+	return returnValue[uint64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) RPop(key string) (string, error) {
+	returns := m.called("RPop", key)
+	return returnValue[string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) RPopOk(key string) (string, bool, error) {
+	returns := m.called("RPopOk", key)
+	return returnValue[string](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) RPopUint64(key string) (uint64, error) {
+	returns := m.called("RPopUint64", key)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) RPopUint64Ok(key string) (uint64, bool, error) {
+	returns := m.called("RPopUint64Ok", key)
+	return returnValue[uint64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) Len(key string) (uint64, error) {
+	returns := m.called("Len", key)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) Range(key string, start int64, stop int64) ([]string, error) {
+	returns := m.called("Range", key, start, stop)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) ListAll(key string) ([]string, error) {
+	returns := m.called("ListAll", key)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) ListAllUint64(key string) ([]uint64, error) {
+	returns := m.called("ListAllUint64", key)
+	return returnValue[[]uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) Get(key string, index int) (string, error) {
+	returns := m.called("Get", key, index)
+	return returnValue[string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) GetOk(key string, index int) (string, bool, error) {
+	returns := m.called("GetOk", key, index)
+	return returnValue[string](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) GetUint64(key string, index int) (uint64, error) {
+	returns := m.called("GetUint64", key, index)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockList) GetUint64Ok(key string, index int) (uint64, bool, error) {
+	returns := m.called("GetUint64Ok", key, index)
+	return returnValue[uint64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockList) Set(key string, index int, value string) error {
+	returns := m.called("Set", key, index, value)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockList) SetUint64(key string, index int, value uint64) error {
+	returns := m.called("SetUint64", key, index, value)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockList) LTrim(key string, start int64, stop int64) error {
+	returns := m.called("LTrim", key, start, stop)
+	return returnValue[error](returns, 0)
+}
+
+// MockSet 实现 go_redis.ISet
+type MockSet struct {
+	Mock
+}
+
+var _ go_redis.ISet = (*MockSet)(nil)
+
+func (m *MockSet) Add(key string, member string) error {
+	returns := m.called("Add", key, member)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockSet) AddBatch(key string, members []any) error {
+	returns := m.called("AddBatch", key, members)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockSet) Members(key string) ([]string, error) {
+	returns := m.called("Members", key)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockSet) IsMember(key string, member string) (bool, error) {
+	returns := m.called("IsMember", key, member)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockSet) Remove(key string, members ...string) error {
+	returns := m.called("Remove", key, members)
+	return returnValue[error](returns, 0)
+}
+
+// MockOrderSet 实现 go_redis.IOrderSet
+type MockOrderSet struct {
+	Mock
+}
+
+var _ go_redis.IOrderSet = (*MockOrderSet)(nil)
+
+func (m *MockOrderSet) Add(key string, member string, score float64) error {
+	returns := m.called("Add", key, member, score)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockOrderSet) AddBatch(key string, members []redis.Z) error {
+	returns := m.called("AddBatch", key, members)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockOrderSet) Remove(key string, member string) (bool, error) {
+	returns := m.called("Remove", key, member)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RemRangeByScore(key string, min float64, max float64) error {
+	returns := m.called("RemRangeByScore", key, min, max)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockOrderSet) Count(key string, min float64, max float64) (int64, error) {
+	returns := m.called("Count", key, min, max)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) TotalCount(key string) (int64, error) {
+	returns := m.called("TotalCount", key)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) IncrBy(key string, member string, increment float64) (float64, error) {
+	returns := m.called("IncrBy", key, member, increment)
+	return returnValue[float64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) Range(key string, start int64, stop int64) ([]string, error) {
+	returns := m.called("Range", key, start, stop)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RevRange(key string, start int64, stop int64) ([]string, error) {
+	returns := m.called("RevRange", key, start, stop)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RevRangeWithScores(key string, start int64, stop int64) ([]redis.Z, error) {
+	returns := m.called("RevRangeWithScores", key, start, stop)
+	return returnValue[[]redis.Z](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RangeByScore(key string, rangeBy go_redis.RangeBy) ([]string, error) {
+	returns := m.called("RangeByScore", key, rangeBy)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RevRangeByScore(key string, rangeBy go_redis.RangeBy) ([]string, error) {
+	returns := m.called("RevRangeByScore", key, rangeBy)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) RevRangeByScoreWithScores(key string, rangeBy go_redis.RangeBy) ([]redis.Z, error) {
+	returns := m.called("RevRangeByScoreWithScores", key, rangeBy)
+	return returnValue[[]redis.Z](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) Score(key string, member string) (float64, error) {
+	returns := m.called("Score", key, member)
+	return returnValue[float64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockOrderSet) ScoreOk(key string, member string) (float64, bool, error) {
+	returns := m.called("ScoreOk", key, member)
+	return returnValue[float64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+// MockBitmap 实现 go_redis.IBitmap
+type MockBitmap struct {
+	Mock
+}
+
+var _ go_redis.IBitmap = (*MockBitmap)(nil)
+
+func (m *MockBitmap) SetBit(key string, offset uint64, value bool) (bool, error) {
+	returns := m.called("SetBit", key, offset, value)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockBitmap) GetBit(key string, offset uint64) (bool, error) {
+	returns := m.called("GetBit", key, offset)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockBitmap) Count(key string, bitRange *go_redis.BitRange) (uint64, error) {
+	returns := m.called("Count", key, bitRange)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockBitmap) BitPos(key string, bit bool, bitRange *go_redis.BitRange) (int64, error) {
+	returns := m.called("BitPos", key, bit, bitRange)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockBitmap) BitOp(op go_redis.BitOperation, dest string, keys ...string) (int64, error) {
+	returns := m.called("BitOp", op, dest, keys)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockBitmap) BitField(key string) *go_redis.BitFieldBuilder {
+	returns := m.called("BitField", key)
+	return returnValue[*go_redis.BitFieldBuilder](returns, 0)
+}
+
+// MockHyperLogLog 实现 go_redis.IHyperLogLog
+type MockHyperLogLog struct {
+	Mock
+}
+
+var _ go_redis.IHyperLogLog = (*MockHyperLogLog)(nil)
+
+func (m *MockHyperLogLog) Add(key string, elements ...string) (bool, error) {
+	returns := m.called("Add", key, elements)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHyperLogLog) Count(keys ...string) (uint64, error) {
+	returns := m.called("Count", keys)
+	return returnValue[uint64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockHyperLogLog) Merge(dest string, sources ...string) error {
+	returns := m.called("Merge", dest, sources)
+	return returnValue[error](returns, 0)
+}
+
+// MockGeo 实现 go_redis.IGeo
+type MockGeo struct {
+	Mock
+}
+
+var _ go_redis.IGeo = (*MockGeo)(nil)
+
+func (m *MockGeo) Add(key string, options *go_redis.GeoAddOptions, locations ...go_redis.GeoLocation) (int64, error) {
+	returns := m.called("Add", key, options, locations)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockGeo) Pos(key string, members ...string) ([]*go_redis.GeoPosition, error) {
+	returns := m.called("Pos", key, members)
+	return returnValue[[]*go_redis.GeoPosition](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockGeo) Dist(key string, member1 string, member2 string, unit go_redis.GeoUnit) (float64, bool, error) {
+	returns := m.called("Dist", key, member1, member2, unit)
+	return returnValue[float64](returns, 0), returnValue[bool](returns, 1), returnValue[error](returns, 2)
+}
+
+func (m *MockGeo) Hash(key string, members ...string) ([]string, error) {
+	returns := m.called("Hash", key, members)
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockGeo) Search(key string) *go_redis.GeoSearchBuilder {
+	returns := m.called("Search", key)
+	return returnValue[*go_redis.GeoSearchBuilder](returns, 0)
+}
+
+// MockJSONDoc 实现 go_redis.IJSONDoc
+type MockJSONDoc struct {
+	Mock
+}
+
+var _ go_redis.IJSONDoc = (*MockJSONDoc)(nil)
+
+func (m *MockJSONDoc) Set(key string, path string, value any, options *go_redis.JSONSetOptions) (bool, error) {
+	returns := m.called("Set", key, path, value, options)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockJSONDoc) Get(key string, paths ...string) (json.RawMessage, error) {
+	returns := m.called("Get", key, paths)
+	return returnValue[json.RawMessage](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockJSONDoc) MGet(path string, keys ...string) ([]json.RawMessage, error) {
+	returns := m.called("MGet", path, keys)
+	return returnValue[[]json.RawMessage](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockJSONDoc) Del(key string, path string) (int64, error) {
+	returns := m.called("Del", key, path)
+	return returnValue[int64](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockJSONDoc) NumIncrBy(key string, path string, value float64) (json.RawMessage, error) {
+	returns := m.called("NumIncrBy", key, path, value)
+	return returnValue[json.RawMessage](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockJSONDoc) ArrAppend(key string, path string, values ...any) ([]int64, error) {
+	returns := m.called("ArrAppend", key, path, values)
+	return returnValue[[]int64](returns, 0), returnValue[error](returns, 1)
+}
+
+// MockSearch 实现 go_redis.ISearch
+type MockSearch struct {
+	Mock
+}
+
+var _ go_redis.ISearch = (*MockSearch)(nil)
+
+func (m *MockSearch) CreateIndex(index string, schema any, options *go_redis.IndexOptions) error {
+	returns := m.called("CreateIndex", index, schema, options)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockSearch) DropIndex(index string, deleteDocs bool) (bool, error) {
+	returns := m.called("DropIndex", index, deleteDocs)
+	return returnValue[bool](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockSearch) Indexes() ([]string, error) {
+	returns := m.called("Indexes")
+	return returnValue[[]string](returns, 0), returnValue[error](returns, 1)
+}
+
+func (m *MockSearch) Query(index string, query string) *go_redis.SearchQuery {
+	returns := m.called("Query", index, query)
+	return returnValue[*go_redis.SearchQuery](returns, 0)
+}
+
+func (m *MockSearch) Aggregate(index string, query string) *go_redis.AggregateQuery {
+	returns := m.called("Aggregate", index, query)
+	return returnValue[*go_redis.AggregateQuery](returns, 0)
+}
+
+func (m *MockSearch) CreateVectorIndex(index string, field string, vector go_redis.VectorFieldOptions, filters []go_redis.SearchField, options *go_redis.IndexOptions) error {
+	returns := m.called("CreateVectorIndex", index, field, vector, filters, options)
+	return returnValue[error](returns, 0)
+}
+
+func (m *MockSearch) KNN(index string, field string, vector []float32, k int) *go_redis.KNNQuery {
+	returns := m.called("KNN", index, field, vector, k)
+	return returnValue[*go_redis.KNNQuery](returns, 0)
+}
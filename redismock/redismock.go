@@ -0,0 +1,105 @@
+// redismock 提供 go_redis 各接口的 mock，记录每次调用，返回值由测试设置，没有设置时返回零值
+//
+//	str := &redismock.MockString{}
+//	str.Return("Get", "value", nil)
+//	r := &redismock.MockRedis{}
+//	r.Return("Strings", str)
+//	...
+//	calls := str.CallsOf("Get")
+package redismock
+
+//go:generate go run gen.go
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Call struct {
+	Method string
+	Args   []any // 可变参数作为一个切片记录
+}
+
+// 所有 mock 共用的调用记录和返回值设置
+type Mock struct {
+	mu      sync.Mutex
+	calls   []Call
+	returns map[string][]any
+	funcs   map[string]func(args []any) []any
+}
+
+// 设置方法的返回值，之后每次调用都返回这些值，nil 表示零值
+func (m *Mock) Return(method string, values ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.returns == nil {
+		m.returns = make(map[string][]any)
+	}
+	m.returns[method] = values
+}
+
+// 根据参数计算返回值，优先于 Return
+func (m *Mock) ReturnFunc(method string, fn func(args []any) []any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.funcs == nil {
+		m.funcs = make(map[string]func(args []any) []any)
+	}
+	m.funcs[method] = fn
+}
+
+// 所有调用，按调用顺序
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// 指定方法的调用
+func (m *Mock) CallsOf(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make([]Call, 0)
+	for _, call := range m.calls {
+		if call.Method == method {
+			results = append(results, call)
+		}
+	}
+	return results
+}
+
+// 清空调用记录和返回值
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+	m.returns = nil
+	m.funcs = nil
+}
+
+func (m *Mock) called(method string, args ...any) []any {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{
+		Method: method,
+		Args:   args,
+	})
+	fn := m.funcs[method]
+	returns := m.returns[method]
+	m.mu.Unlock()
+	if fn != nil {
+		return fn(args)
+	}
+	return returns
+}
+
+func returnValue[T any](returns []any, i int) T {
+	var zero T
+	if i >= len(returns) || returns[i] == nil {
+		return zero
+	}
+	value, ok := returns[i].(T)
+	if !ok {
+		panic(fmt.Sprintf("redismock: return value %d is %T, want %T", i, returns[i], zero))
+	}
+	return value
+}
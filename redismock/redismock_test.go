@@ -0,0 +1,73 @@
+package redismock_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	go_test_ "github.com/pefish/go-test"
+
+	go_redis "github.com/pefish/go-redis"
+	"github.com/pefish/go-redis/redismock"
+)
+
+// 调用方的代码只依赖接口
+func loadToken(r go_redis.IRedis, user string) (string, error) {
+	token, found, err := r.Strings().GetOk("token:" + user)
+	if err != nil || found {
+		return token, err
+	}
+	token = "new-" + user
+	if err := r.Strings().Set("token:"+user, token, time.Hour); err != nil {
+		return ``, err
+	}
+	if _, err := r.Lists().RPush("issued", user); err != nil {
+		return ``, err
+	}
+	return token, nil
+}
+
+func TestMock(t *testing.T) {
+	str := &redismock.MockString{}
+	list := &redismock.MockList{}
+	r := &redismock.MockRedis{}
+	r.Return("Strings", str)
+	r.Return("Lists", list)
+
+	// 没有设置返回值时返回零值
+	token, err := loadToken(r, "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "new-a", token)
+	go_test_.Equal(t, 1, len(str.CallsOf("GetOk")))
+	sets := str.CallsOf("Set")
+	go_test_.Equal(t, 1, len(sets))
+	go_test_.Equal(t, []any{"token:a", "new-a", time.Hour}, sets[0].Args)
+	go_test_.Equal(t, []any{"issued", []string{"a"}}, list.Calls()[0].Args)
+
+	str.Return("GetOk", "cached", true, nil)
+	token, err = loadToken(r, "b")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "cached", token)
+	go_test_.Equal(t, 1, len(str.CallsOf("Set")))
+
+	str.ReturnFunc("GetOk", func(args []any) []any {
+		if args[0] == "token:c" {
+			return []any{nil, false, errors.New("boom")}
+		}
+		return nil
+	})
+	_, err = loadToken(r, "c")
+	go_test_.Equal(t, "boom", err.Error())
+
+	str.Reset()
+	go_test_.Equal(t, 0, len(str.Calls()))
+}
+
+func TestMock_WrongReturnType(t *testing.T) {
+	str := &redismock.MockString{}
+	str.Return("Get", 1)
+	defer func() {
+		go_test_.NotEqual(t, nil, recover())
+	}()
+	_, _ = str.Get("key")
+}
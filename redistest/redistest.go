@@ -0,0 +1,123 @@
+// redistest 提供一个由进程内 RESP 服务（miniredis）支撑的 RedisType，单元测试不需要真实的 Redis。
+// 支持字符串、哈希、列表、集合、有序集合、过期时间（时钟可控）、发布订阅和 Lua 脚本（锁的释放），
+// 测试结束时通过 t.Cleanup 自动关闭
+//
+//	func TestXxx(t *testing.T) {
+//		instance, server := redistest.New(t, nil)
+//		instance.String.Set("key", "value", time.Minute)
+//		server.Advance(2 * time.Minute)
+//		...
+//	}
+package redistest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_redis "github.com/pefish/go-redis"
+)
+
+type Options struct {
+	Logger   i_logger.ILogger // 默认 i_logger.DefaultLogger
+	Timeout  time.Duration    // 命令超时，默认 5 秒
+	Password string           // 不为空时服务端需要认证
+	Db       uint64
+	Now      time.Time // 服务端时钟的起始时间，默认当前时间
+}
+
+// 进程内 Redis 服务，可以直接调用 miniredis 的方法检查或修改数据
+type Server struct {
+	*miniredis.Miniredis
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+// 启动一个进程内 Redis 服务并返回已连接的 RedisType，options 可以为 nil
+func New(t testing.TB, options *Options) (*go_redis.RedisType, *Server) {
+	t.Helper()
+	server := NewServer(t, options)
+	if options == nil {
+		options = &Options{}
+	}
+	logger := options.Logger
+	if logger == nil {
+		logger = &i_logger.DefaultLogger
+	}
+	timeout := options.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	instance := go_redis.New(logger, timeout)
+	err := instance.Connect(&go_redis.Configuration{
+		Url:      server.Addr(),
+		Db:       options.Db,
+		Password: options.Password,
+	})
+	if err != nil {
+		t.Fatalf("redistest: connect failed: %v", err)
+	}
+	t.Cleanup(instance.Close)
+	return instance, server
+}
+
+// 只启动服务，需要自己创建和连接 RedisType 时使用，例如测试 Configuration 的其他选项
+func NewServer(t testing.TB, options *Options) *Server {
+	t.Helper()
+	if options == nil {
+		options = &Options{}
+	}
+	m := miniredis.NewMiniRedis()
+	if options.Password != `` {
+		m.RequireAuth(options.Password)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatalf("redistest: start server failed: %v", err)
+	}
+	t.Cleanup(m.Close)
+
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	server := &Server{
+		Miniredis: m,
+		now:       now,
+	}
+	m.SetTime(now)
+	return server
+}
+
+// 服务端的当前时间，只会被 Advance 和 SetNow 改变
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// 把服务端时钟往前拨 d，到期的 key 会被删除，TIME 命令和 EXPIREAT 等也使用新的时间
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	s.Miniredis.SetTime(s.now)
+	s.Miniredis.FastForward(d)
+}
+
+// 把服务端时钟设置到 now，比当前时间晚时等同于 Advance
+func (s *Server) SetNow(now time.Time) {
+	s.mu.Lock()
+	d := now.Sub(s.now)
+	s.mu.Unlock()
+	if d > 0 {
+		s.Advance(d)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+	s.Miniredis.SetTime(now)
+}
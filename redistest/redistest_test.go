@@ -0,0 +1,97 @@
+package redistest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/pefish/go-redis/redistest"
+)
+
+func TestNew(t *testing.T) {
+	instance, server := redistest.New(t, nil)
+
+	go_test_.Equal(t, nil, instance.String.Set("str", "value", time.Minute))
+	value, err := instance.String.Get("str")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "value", value)
+	server.CheckGet(t, "str", "value")
+
+	go_test_.Equal(t, nil, instance.Hash.Set("hash", "f", "1"))
+	count, err := instance.Hash.IncrBy("hash", "f", 2)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, int64(3), count)
+
+	_, err = instance.List.RPush("list", "a", "b")
+	go_test_.Equal(t, nil, err)
+	items, err := instance.List.ListAll("list")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"a", "b"}, items)
+
+	go_test_.Equal(t, nil, instance.Set.Add("set", "a"))
+	isMember, err := instance.Set.IsMember("set", "a")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, isMember)
+
+	go_test_.Equal(t, nil, instance.OrderSet.Add("zset", "a", 2))
+	go_test_.Equal(t, nil, instance.OrderSet.Add("zset", "b", 1))
+	members, err := instance.OrderSet.Range("zset", 0, -1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"b", "a"}, members)
+}
+
+func TestServer_Advance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	instance, server := redistest.New(t, &redistest.Options{Now: start})
+
+	ok, err := instance.String.SetNX("ttl", "1", 10*time.Second)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, ok)
+	server.Advance(5 * time.Second)
+	go_test_.Equal(t, true, server.Exists("ttl"))
+	server.Advance(6 * time.Second)
+	go_test_.Equal(t, false, server.Exists("ttl"))
+	go_test_.Equal(t, start.Add(11*time.Second), server.Now())
+
+	serverTime, err := instance.Db.Time(context.Background()).Result()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, start.Add(11*time.Second).Unix(), serverTime.Unix())
+
+	server.SetNow(start.Add(time.Hour))
+	go_test_.Equal(t, start.Add(time.Hour), server.Now())
+}
+
+func TestPubSubAndLock(t *testing.T) {
+	instance, _ := redistest.New(t, &redistest.Options{Password: "password"})
+
+	messages := instance.Subscribe("channel")
+	time.Sleep(100 * time.Millisecond)
+	count, err := instance.Publish("channel", "hello")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(1), count)
+	var message *redis.Message
+	select {
+	case message = <-messages:
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+	go_test_.Equal(t, "hello", message.Payload)
+
+	owner := uuid.New().String()
+	locked, err := instance.GetLock("lock", owner, time.Minute)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, locked)
+	// 别人的 value 释放不了
+	go_test_.Equal(t, nil, instance.ReleaseLock("lock", "other"))
+	exists, err := instance.Exists("lock")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, exists)
+	go_test_.Equal(t, nil, instance.ReleaseLock("lock", owner))
+	exists, err = instance.Exists("lock")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, exists)
+}
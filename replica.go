@@ -0,0 +1,165 @@
+package go_redis
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+type ReplicaPolicy int
+
+const (
+	ReplicaRandom        ReplicaPolicy = iota // 随机选择
+	ReplicaRoundRobin                         // 轮询
+	ReplicaLowestLatency                      // 选最近耗时（指数加权平均）最低的副本，出错的副本 10 秒内不优先选择
+)
+
+// 会被路由到副本的只读命令
+var replicaReadCommands = map[string]bool{
+	"get": true, "mget": true, "strlen": true, "getrange": true,
+	"exists": true, "keys": true, "scan": true, "ttl": true, "pttl": true, "type": true,
+	"hget": true, "hmget": true, "hgetall": true, "hexists": true, "hlen": true, "hkeys": true, "hvals": true,
+	"hrandfield": true, "hscan": true, "hstrlen": true,
+	"llen": true, "lrange": true, "lindex": true,
+	"smembers": true, "sismember": true, "smismember": true, "scard": true, "srandmember": true, "sscan": true,
+	"zrange": true, "zrevrange": true, "zrangebyscore": true, "zrevrangebyscore": true, "zscore": true,
+	"zmscore": true, "zcount": true, "zcard": true, "zrank": true, "zrevrank": true, "zscan": true,
+	"getbit": true, "bitcount": true, "bitpos": true, "pfcount": true,
+	"geopos": true, "geodist": true, "geohash": true, "geosearch": true,
+	"json.get": true, "json.mget": true, "ft.search": true, "ft.aggregate": true,
+	"fcall_ro": true,
+}
+
+// 读写分离。封装方法发起的只读命令在主库的 ProcessHook 里转发到副本执行，副本出错（key 不存在除外）时回退到主库
+type replicaRouter struct {
+	logger    i_logger.ILogger
+	clients   []*redis.Client
+	addrs     []string
+	policy    ReplicaPolicy
+	next      atomic.Uint64
+	latencies []atomic.Int64 // 纳秒，成功请求耗时的指数加权平均
+	failedAt  []atomic.Int64 // 最近一次出错的时间，unix 纳秒
+	penalty   time.Duration  // 出错后多长时间内不优先选择，过后按耗时重新参与选择
+}
+
+func newReplicaRouter(logger i_logger.ILogger, addrs []string, policy ReplicaPolicy, options *redis.Options, timeout time.Duration) *replicaRouter {
+	r := &replicaRouter{
+		logger:    logger,
+		clients:   make([]*redis.Client, 0, len(addrs)),
+		addrs:     make([]string, 0, len(addrs)),
+		policy:    policy,
+		latencies: make([]atomic.Int64, len(addrs)),
+		failedAt:  make([]atomic.Int64, len(addrs)),
+		penalty:   10 * time.Second,
+	}
+	for _, addr := range addrs {
+		if !strings.Contains(addr, ":") {
+			addr += ":6379"
+		}
+		replicaOptions := *options
+		replicaOptions.Addr = addr
+		replicaOptions.OnConnect = nil
+		r.clients = append(r.clients, redis.NewClient(&replicaOptions).WithTimeout(timeout))
+		r.addrs = append(r.addrs, addr)
+	}
+	return r
+}
+
+func (r *replicaRouter) close() {
+	for i, client := range r.clients {
+		if err := client.Close(); err != nil {
+			r.logger.ErrorF(`Redis replica close failed. addr: %s, err: %s`, r.addrs[i], err)
+		}
+	}
+}
+
+func (r *replicaRouter) pick() int {
+	switch r.policy {
+	case ReplicaRoundRobin:
+		return int((r.next.Add(1) - 1) % uint64(len(r.clients)))
+	case ReplicaLowestLatency:
+		// 最近出错的副本排在没出错的后面，全部出错时在其中选耗时最低的
+		now := time.Now().UnixNano()
+		best, bestFailed, bestLatency := 0, true, int64(math.MaxInt64)
+		for i := range r.latencies {
+			failed := now-r.failedAt[i].Load() < int64(r.penalty)
+			latency := r.latencies[i].Load()
+			if (bestFailed && !failed) || (failed == bestFailed && latency < bestLatency) {
+				best, bestFailed, bestLatency = i, failed, latency
+			}
+		}
+		return best
+	}
+	return rand.IntN(len(r.clients))
+}
+
+func (r *replicaRouter) observe(i int, latency time.Duration, failed bool) {
+	if failed {
+		// 不计入耗时，否则不再被选中的副本没有新的样本，恢复后也永远选不到
+		r.failedAt[i].Store(time.Now().UnixNano())
+		return
+	}
+	old := r.latencies[i].Load()
+	if old == 0 {
+		r.latencies[i].Store(int64(latency))
+		return
+	}
+	r.latencies[i].Store(old*4/5 + int64(latency)/5)
+}
+
+func (r *replicaRouter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (r *replicaRouter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !replicaReadCommands[cmd.Name()] || OperationFromContext(ctx) == `` || isForcePrimary(ctx) {
+			return next(ctx, cmd)
+		}
+		i := r.pick()
+		start := time.Now()
+		err := r.clients[i].Process(ctx, cmd)
+		failed := err != nil && !errors.Is(err, redis.Nil)
+		r.observe(i, time.Since(start), failed)
+		if !failed {
+			setCommandAddr(ctx, r.addrs[i])
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		r.logger.WarnF(`Redis replica read failed, fall back to primary. addr: %s, err: %s`, r.addrs[i], err)
+		cmd.SetErr(nil)
+		return next(ctx, cmd)
+	}
+}
+
+func (r *replicaRouter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+type forcePrimaryKey struct{}
+
+// 返回的 ctx 上的只读命令都读主库，用于写后立即读（read-your-writes）：
+//
+//	instance.WithContext(go_redis.ForcePrimary(ctx)).String.Get("key")
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+func isForcePrimary(ctx context.Context) bool {
+	force, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return force
+}
+
+// 返回一个所有命令都走主库的视图，等同于 WithContext(ForcePrimary(ctx))
+func (t *RedisType) Primary() *RedisType {
+	return t.WithContext(ForcePrimary(t.baseCtx))
+}
@@ -0,0 +1,110 @@
+package go_redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	i_logger "github.com/pefish/go-interface/i-logger"
+	go_test_ "github.com/pefish/go-test"
+)
+
+// 三个独立的服务，各自写入不同的值，通过读到的值判断命令落在哪里
+func newReplicaInstance(t *testing.T, policy ReplicaPolicy) (*RedisType, []*miniredis.Miniredis) {
+	servers := make([]*miniredis.Miniredis, 0, 3)
+	for _, name := range []string{"primary", "replica1", "replica2"} {
+		server := miniredis.RunT(t)
+		server.Set("where", name)
+		servers = append(servers, server)
+	}
+	instance := New(&i_logger.DefaultLogger, 5*time.Second)
+	err := instance.Connect(&Configuration{
+		Url:           servers[0].Addr(),
+		Replicas:      []string{servers[1].Addr(), servers[2].Addr()},
+		ReplicaPolicy: policy,
+	})
+	go_test_.Equal(t, nil, err)
+	t.Cleanup(instance.Close)
+	return instance, servers
+}
+
+func TestReplica_RoundRobin(t *testing.T) {
+	instance, servers := newReplicaInstance(t, ReplicaRoundRobin)
+
+	results := make([]string, 0)
+	for i := 0; i < 4; i++ {
+		value, err := instance.String.Get("where")
+		go_test_.Equal(t, nil, err)
+		results = append(results, value)
+	}
+	go_test_.Equal(t, []string{"replica1", "replica2", "replica1", "replica2"}, results)
+
+	// 写命令和直接通过 Db 执行的命令都走主库
+	go_test_.Equal(t, nil, instance.String.Set("written", "1", 0))
+	servers[0].CheckGet(t, "written", "1")
+	go_test_.Equal(t, false, servers[1].Exists("written"))
+	value, err := instance.Db.Get(context.Background(), "where").Result()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "primary", value)
+
+	// 强制读主库
+	value, err = instance.Primary().String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "primary", value)
+	value, err = instance.WithContext(ForcePrimary(context.Background())).String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "primary", value)
+
+	// 副本上没有的 key 不回退
+	_, found, err := instance.String.GetOk("written")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+}
+
+func TestReplica_Fallback(t *testing.T) {
+	instance, servers := newReplicaInstance(t, ReplicaRandom)
+	servers[1].Close()
+	servers[2].SetError("LOADING")
+	for i := 0; i < 4; i++ {
+		value, err := instance.String.Get("where")
+		go_test_.Equal(t, nil, err)
+		go_test_.Equal(t, "primary", value)
+	}
+}
+
+func TestReplica_LowestLatency(t *testing.T) {
+	instance, _ := newReplicaInstance(t, ReplicaLowestLatency)
+	instance.replicas.latencies[0].Store(int64(10 * time.Millisecond))
+	instance.replicas.latencies[1].Store(int64(time.Millisecond))
+	value, err := instance.String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "replica2", value)
+	instance.replicas.observe(1, 0, true)
+	value, err = instance.String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "replica1", value)
+}
+
+func TestReplica_LowestLatencyRecover(t *testing.T) {
+	instance, _ := newReplicaInstance(t, ReplicaLowestLatency)
+	instance.replicas.penalty = 100 * time.Millisecond
+	instance.replicas.latencies[0].Store(int64(10 * time.Millisecond))
+	instance.replicas.latencies[1].Store(int64(time.Millisecond))
+
+	instance.replicas.observe(1, 0, true)
+	value, err := instance.String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "replica1", value)
+
+	// 惩罚时间过后，恢复的副本按耗时重新被选中
+	time.Sleep(150 * time.Millisecond)
+	value, err = instance.String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "replica2", value)
+
+	// 全部出错时仍然在其中选耗时最低的
+	instance.replicas.observe(0, 0, true)
+	instance.replicas.observe(1, 0, true)
+	go_test_.Equal(t, 1, instance.replicas.pick())
+}
@@ -0,0 +1,200 @@
+package go_redis
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// 已注册的脚本，RegisterScripts 使用
+type ScriptSource interface {
+	Name() string
+	Source() string
+	Hash() string
+}
+
+// Lua 脚本。通过 EVALSHA 执行，服务端没有缓存（NOSCRIPT）时自动改用 EVAL。
+// 声明 key 和参数的个数，调用时会检查；结果通过 decode 转换成 T
+//
+//	var incrIfExists = go_redis.NewScript[int64]("incr_if_exists",
+//		`if redis.call('exists', KEYS[1]) == 1 then return redis.call('incr', KEYS[1]) else return 0 end`, 1, 0, nil)
+//	count, err := incrIfExists.Run(instance, []string{"counter"})
+type Script[T any] struct {
+	name    string
+	src     string
+	script  *redis.Script
+	numKeys int
+	numArgs int
+	decode  func(result any) (T, error)
+}
+
+// numArgs 为 -1 时不检查参数个数。decode 为 nil 时使用默认转换：
+// 整数、字符串结果按 GetAs 的规则转换成 T，数组结果可以转换成 []E，E 同样按 GetAs 的规则转换
+func NewScript[T any](name string, src string, numKeys int, numArgs int, decode func(result any) (T, error)) *Script[T] {
+	if decode == nil {
+		decode = decodeScriptResult[T]
+	}
+	return &Script[T]{
+		name:    name,
+		src:     src,
+		script:  redis.NewScript(src),
+		numKeys: numKeys,
+		numArgs: numArgs,
+		decode:  decode,
+	}
+}
+
+func (s *Script[T]) Name() string {
+	return s.name
+}
+
+func (s *Script[T]) Source() string {
+	return s.src
+}
+
+func (s *Script[T]) Hash() string {
+	return s.script.Hash()
+}
+
+// 执行脚本，key 会加上 t 的前缀。脚本返回 nil（Lua 的 false、nil）时返回零值
+func (s *Script[T]) Run(t *RedisType, keys []string, args ...any) (T, error) {
+	result, _, err := s.RunOk(t, keys, args...)
+	return result, err
+}
+
+// 同 Run，脚本返回 nil 时 found_ 为 false
+func (s *Script[T]) RunOk(t *RedisType, keys []string, args ...any) (result_ T, found_ bool, err_ error) {
+	return s.run(t.ctx("script."+s.name), t, keys, args...)
+}
+
+func (s *Script[T]) run(ctx context.Context, t *RedisType, keys []string, args ...any) (result_ T, found_ bool, err_ error) {
+	var zero T
+	if len(keys) != s.numKeys {
+		return zero, false, errors.Errorf("<script: %s> want %d keys, got %d.", s.name, s.numKeys, len(keys))
+	}
+	if s.numArgs >= 0 && len(args) != s.numArgs {
+		return zero, false, errors.Errorf("<script: %s> want %d args, got %d.", s.name, s.numArgs, len(args))
+	}
+	t.scripts.register(s)
+	prefixed := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixed = append(prefixed, t.prefix.key(key))
+	}
+	result, err := s.script.Run(ctx, t.Db, prefixed, args...).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return zero, false, nil
+		}
+		return zero, false, errors.Wrapf(err, "<script: %s> <keys: %v>", s.name, prefixed)
+	}
+	value, err := s.decode(result)
+	if err != nil {
+		return zero, false, errors.WithMessagef(err, "<script: %s> decode result failed", s.name)
+	}
+	return value, true, nil
+}
+
+func decodeScriptResult[T any](result any) (T, error) {
+	if value, ok := result.(T); ok {
+		return value, nil
+	}
+	var value T
+	if err := decodeScriptValue(result, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+func decodeScriptValue(result any, dst any) error {
+	if items, ok := result.([]any); ok {
+		rv := reflect.ValueOf(dst).Elem()
+		if rv.Kind() != reflect.Slice {
+			return errors.Errorf("array result to %T failed.", dst)
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeScriptValue(item, slice.Index(i).Addr().Interface()); err != nil {
+				return errors.WithMessagef(err, "<index: %d>", i)
+			}
+		}
+		rv.Set(slice)
+		return nil
+	}
+	str, err := formatValue(result)
+	if err != nil {
+		return err
+	}
+	return parseValueTo(str, dst)
+}
+
+type scriptRegistry struct {
+	mu      sync.RWMutex
+	scripts map[string]ScriptSource // sha1 → 脚本
+}
+
+func newScriptRegistry() *scriptRegistry {
+	return &scriptRegistry{
+		scripts: make(map[string]ScriptSource),
+	}
+}
+
+// 注册脚本，返回是否是新注册的
+func (r *scriptRegistry) register(script ScriptSource) bool {
+	r.mu.RLock()
+	_, ok := r.scripts[script.Hash()]
+	r.mu.RUnlock()
+	if ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.scripts[script.Hash()]; ok {
+		return false
+	}
+	r.scripts[script.Hash()] = script
+	return true
+}
+
+func (r *scriptRegistry) list() []ScriptSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	results := make([]ScriptSource, 0, len(r.scripts))
+	for _, script := range r.scripts {
+		results = append(results, script)
+	}
+	return results
+}
+
+// 在新连接上加载所有脚本，Redis 重启后脚本缓存会丢失，重连时重新加载
+func (r *scriptRegistry) preload(ctx context.Context, cn *redis.Conn) error {
+	scripts := r.list()
+	if len(scripts) == 0 {
+		return nil
+	}
+	_, err := cn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, script := range scripts {
+			pipe.ScriptLoad(ctx, script.Source())
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "preload scripts failed")
+	}
+	return nil
+}
+
+// 注册脚本，连接上之后每个新连接都会预先加载（SCRIPT LOAD）。已经连接时立即加载一次
+func (t *RedisType) RegisterScripts(scripts ...ScriptSource) error {
+	for _, script := range scripts {
+		if !t.scripts.register(script) || t.Db == nil {
+			continue
+		}
+		if err := t.Db.ScriptLoad(t.ctx("script.load"), script.Source()).Err(); err != nil {
+			return errors.Wrapf(err, "<script: %s>", script.Name())
+		}
+	}
+	return nil
+}
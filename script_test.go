@@ -0,0 +1,72 @@
+package go_redis
+
+import (
+	"context"
+	"testing"
+
+	go_test_ "github.com/pefish/go-test"
+)
+
+var testEchoScript = NewScript[[]string]("test.echo", `return {KEYS[1], ARGV[1], ARGV[2]}`, 1, 2, nil)
+var testIncrScript = NewScript[uint64]("test.incr", `return redis.call('incrby', KEYS[1], ARGV[1])`, 1, 1, nil)
+var testNilScript = NewScript[string]("test.nil", `return redis.call('get', KEYS[1])`, 1, 0, nil)
+
+func scriptExists(t *testing.T, instance *RedisType, script ScriptSource) bool {
+	results, err := instance.Db.ScriptExists(context.Background(), script.Hash()).Result()
+	go_test_.Equal(t, nil, err)
+	return results[0]
+}
+
+func TestScript_Run(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	view := instance.WithPrefix("p:")
+
+	results, err := testEchoScript.Run(view, []string{"k"}, "a", 1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"p:k", "a", "1"}, results)
+
+	count, err := testIncrScript.Run(instance, []string{"counter"}, 2)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(2), count)
+
+	_, err = testIncrScript.Run(instance, []string{"counter"})
+	go_test_.NotEqual(t, nil, err)
+	_, err = testIncrScript.Run(instance, nil, 1)
+	go_test_.NotEqual(t, nil, err)
+
+	value, found, err := testNilScript.RunOk(instance, []string{"none"})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	go_test_.Equal(t, "", value)
+	server.Set("some", "1")
+	value, err = testNilScript.Run(instance, []string{"some"})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "1", value)
+
+	// 服务端脚本缓存被清空后自动改用 EVAL
+	go_test_.Equal(t, nil, instance.Db.ScriptFlush(context.Background()).Err())
+	count, err = testIncrScript.Run(instance, []string{"counter"}, 1)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, uint64(3), count)
+}
+
+func TestScript_Preload(t *testing.T) {
+	instance, server := newMiniRedisInstance(t)
+	// 连接时已经加载了锁的脚本
+	go_test_.Equal(t, true, scriptExists(t, instance, releaseLockScript))
+
+	go_test_.Equal(t, false, scriptExists(t, instance, testIncrScript))
+	go_test_.Equal(t, nil, instance.RegisterScripts(testIncrScript))
+	go_test_.Equal(t, true, scriptExists(t, instance, testIncrScript))
+
+	// 通过 Run 用过的脚本也会在重连时加载
+	_, err := testEchoScript.Run(instance, []string{"k"}, "a", "b")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, nil, instance.Db.ScriptFlush(context.Background()).Err())
+	server.Close()
+	go_test_.Equal(t, nil, server.Restart())
+	_ = instance.Db.Ping(context.Background()).Err()
+	go_test_.Equal(t, true, scriptExists(t, instance, releaseLockScript))
+	go_test_.Equal(t, true, scriptExists(t, instance, testIncrScript))
+	go_test_.Equal(t, true, scriptExists(t, instance, testEchoScript))
+}
@@ -0,0 +1,352 @@
+package go_redis
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+
+	i_logger "github.com/pefish/go-interface/i-logger"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// 服务端没有加载 RediSearch 模块，用 errors.Is 判断
+var ErrSearchModuleNotLoaded = errors.New("redis: RediSearch module not loaded")
+
+// RediSearch 全文检索和二级索引，索引建在 HashType 写入的哈希表上。索引名和 key 前缀都会加上 t 的前缀
+type SearchType struct {
+	db      *redis.Client
+	logger  i_logger.ILogger
+	prefix  keyPrefix
+	baseCtx context.Context
+}
+
+type SearchFieldType string
+
+const (
+	SearchText    SearchFieldType = "TEXT"
+	SearchTag     SearchFieldType = "TAG"
+	SearchNumeric SearchFieldType = "NUMERIC"
+	SearchGeo     SearchFieldType = "GEO"    // 值是 "经度,纬度"
+	SearchVector  SearchFieldType = "VECTOR" // 值是向量的二进制
+)
+
+type VectorAlgorithm string
+
+const (
+	VectorFlat VectorAlgorithm = "FLAT" // 暴力搜索，结果精确，适合数据量小的场景
+	VectorHNSW VectorAlgorithm = "HNSW" // 近似搜索，数据量大时更快
+)
+
+type VectorDistance string
+
+const (
+	VectorL2     VectorDistance = "L2"
+	VectorIP     VectorDistance = "IP"
+	VectorCosine VectorDistance = "COSINE"
+)
+
+// 为 0 的参数使用服务端的默认值
+type VectorFieldOptions struct {
+	Algorithm      VectorAlgorithm // 默认 FLAT
+	Type           string          // 元素类型，默认 FLOAT32
+	Dim            int             // 维度，必填
+	Distance       VectorDistance  // 默认 COSINE
+	InitialCap     int             // 预分配的向量个数
+	BlockSize      int             // FLAT：每次扩容的向量个数
+	M              int             // HNSW：每个节点的最大邻居数
+	EFConstruction int             // HNSW：建图时的候选列表大小
+	EFRuntime      int             // HNSW：查询时的候选列表大小，KNNQuery.EFRuntime 可以单独指定
+}
+
+// 索引中的一个字段
+type SearchField struct {
+	Name          string // 哈希表中的字段名
+	Type          SearchFieldType
+	Sortable      bool
+	NoIndex       bool                // 不建索引，只用于排序或返回
+	NoStem        bool                // TEXT：不做词干提取
+	Weight        float64             // TEXT：相关性权重，默认 1
+	Separator     string              // TAG：分隔符，默认 ,
+	CaseSensitive bool                // TAG：区分大小写
+	Vector        *VectorFieldOptions // VECTOR：必填
+}
+
+// 从结构体解析索引字段。字段名取 `redis` 标签（和 SetStruct 一致），`search` 标签指定类型和选项，没有 search 标签的字段不建索引：
+//
+//	type Product struct {
+//		Title     string                 `redis:"title" search:"text,weight=2,sortable"`
+//		Category  string                 `redis:"category" search:"tag,separator=|"`
+//		Price     float64                `redis:"price" search:"numeric,sortable"`
+//		Location  string                 `redis:"location" search:"geo"`
+//		Embedding go_redis.Float32Vector `redis:"embedding" search:"vector,algorithm=hnsw,dim=128,distance=cosine,m=16"`
+//	}
+func SearchSchema(schema any) ([]SearchField, error) {
+	typ := reflect.TypeOf(schema)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.Errorf("schema must be a struct or pointer to struct, got %T.", schema)
+	}
+	fields := make([]SearchField, 0)
+	for _, structField := range cachedStructFields(typ) {
+		tag, ok := typ.FieldByIndex(structField.index).Tag.Lookup("search")
+		if !ok || tag == "-" {
+			continue
+		}
+		field, err := parseSearchTag(structField.name, tag)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "<field: %s>", structField.name)
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, errors.Errorf("%s has no field with search tag.", typ)
+	}
+	return fields, nil
+}
+
+func parseSearchTag(name string, tag string) (SearchField, error) {
+	parts := strings.Split(tag, ",")
+	field := SearchField{
+		Name: name,
+		Type: SearchFieldType(strings.ToUpper(strings.TrimSpace(parts[0]))),
+	}
+	if field.Type == SearchVector {
+		field.Vector = &VectorFieldOptions{}
+	}
+	for _, part := range parts[1:] {
+		option, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		var err error
+		switch strings.ToLower(option) {
+		case "sortable":
+			field.Sortable = true
+		case "noindex":
+			field.NoIndex = true
+		case "nostem":
+			field.NoStem = true
+		case "casesensitive":
+			field.CaseSensitive = true
+		case "weight":
+			field.Weight, err = strconv.ParseFloat(value, 64)
+		case "separator":
+			field.Separator = value
+		case "algorithm", "type", "dim", "distance", "initial_cap", "block_size", "m", "ef_construction", "ef_runtime":
+			if field.Vector == nil {
+				return field, errors.Errorf("<option: %s> only for vector field.", option)
+			}
+			switch strings.ToLower(option) {
+			case "algorithm":
+				field.Vector.Algorithm = VectorAlgorithm(strings.ToUpper(value))
+			case "type":
+				field.Vector.Type = strings.ToUpper(value)
+			case "dim":
+				field.Vector.Dim, err = strconv.Atoi(value)
+			case "distance":
+				field.Vector.Distance = VectorDistance(strings.ToUpper(value))
+			case "initial_cap":
+				field.Vector.InitialCap, err = strconv.Atoi(value)
+			case "block_size":
+				field.Vector.BlockSize, err = strconv.Atoi(value)
+			case "m":
+				field.Vector.M, err = strconv.Atoi(value)
+			case "ef_construction":
+				field.Vector.EFConstruction, err = strconv.Atoi(value)
+			case "ef_runtime":
+				field.Vector.EFRuntime, err = strconv.Atoi(value)
+			}
+		default:
+			return field, errors.Errorf("<option: %s> unknown.", option)
+		}
+		if err != nil {
+			return field, errors.Wrapf(err, "<option: %s>", option)
+		}
+	}
+	return field, nil
+}
+
+func (f *SearchField) args() ([]any, error) {
+	args := []any{f.Name, string(f.Type)}
+	switch f.Type {
+	case SearchText:
+		if f.Weight != 0 {
+			args = append(args, "WEIGHT", f.Weight)
+		}
+		if f.NoStem {
+			args = append(args, "NOSTEM")
+		}
+	case SearchTag:
+		if f.Separator != "" {
+			args = append(args, "SEPARATOR", f.Separator)
+		}
+		if f.CaseSensitive {
+			args = append(args, "CASESENSITIVE")
+		}
+	case SearchNumeric, SearchGeo:
+	case SearchVector:
+		vectorArgs, err := f.Vector.args()
+		if err != nil {
+			return nil, err
+		}
+		return append(args, vectorArgs...), nil
+	default:
+		return nil, errors.Errorf("<type: %s> unsupported.", f.Type)
+	}
+	if f.Sortable {
+		args = append(args, "SORTABLE")
+	}
+	if f.NoIndex {
+		args = append(args, "NOINDEX")
+	}
+	return args, nil
+}
+
+func (o *VectorFieldOptions) args() ([]any, error) {
+	if o == nil || o.Dim <= 0 {
+		return nil, errors.New("vector dim is required.")
+	}
+	algorithm := o.Algorithm
+	if algorithm == "" {
+		algorithm = VectorFlat
+	}
+	if algorithm != VectorFlat && algorithm != VectorHNSW {
+		return nil, errors.Errorf("<algorithm: %s> unsupported.", algorithm)
+	}
+	typ := o.Type
+	if typ == "" {
+		typ = "FLOAT32"
+	}
+	distance := o.Distance
+	if distance == "" {
+		distance = VectorCosine
+	}
+	attributes := []any{"TYPE", typ, "DIM", o.Dim, "DISTANCE_METRIC", string(distance)}
+	if o.InitialCap > 0 {
+		attributes = append(attributes, "INITIAL_CAP", o.InitialCap)
+	}
+	if algorithm == VectorFlat {
+		if o.M > 0 || o.EFConstruction > 0 || o.EFRuntime > 0 {
+			return nil, errors.New("M, EF_CONSTRUCTION and EF_RUNTIME are only for HNSW.")
+		}
+		if o.BlockSize > 0 {
+			attributes = append(attributes, "BLOCK_SIZE", o.BlockSize)
+		}
+	} else {
+		if o.BlockSize > 0 {
+			return nil, errors.New("BLOCK_SIZE is only for FLAT.")
+		}
+		if o.M > 0 {
+			attributes = append(attributes, "M", o.M)
+		}
+		if o.EFConstruction > 0 {
+			attributes = append(attributes, "EF_CONSTRUCTION", o.EFConstruction)
+		}
+		if o.EFRuntime > 0 {
+			attributes = append(attributes, "EF_RUNTIME", o.EFRuntime)
+		}
+	}
+	return append([]any{string(algorithm), len(attributes)}, attributes...), nil
+}
+
+type IndexOptions struct {
+	Prefixes  []string // 要索引的 key 前缀（会加上 t 的前缀），为空时是 t 的前缀，t 也没有前缀时索引所有哈希表
+	Filter    string   // 过滤表达式，只索引满足条件的哈希表
+	Language  string   // TEXT 字段的默认语言
+	StopWords []string // nil 时使用默认停用词，空切片表示不使用停用词
+}
+
+// 创建索引，schema 是带 search 标签的结构体（见 SearchSchema）或者 []SearchField。options 可以为 nil
+func (t *SearchType) CreateIndex(index string, schema any, options *IndexOptions) error {
+	index = t.prefix.key(index)
+	fields, ok := schema.([]SearchField)
+	if !ok {
+		var err error
+		fields, err = SearchSchema(schema)
+		if err != nil {
+			return errors.WithMessagef(err, "<index: %s>", index)
+		}
+	}
+	if options == nil {
+		options = &IndexOptions{}
+	}
+	args := []any{"FT.CREATE", index, "ON", "HASH"}
+	prefixes := make([]string, 0, len(options.Prefixes))
+	for _, prefix := range options.Prefixes {
+		prefixes = append(prefixes, t.prefix.key(prefix))
+	}
+	if len(prefixes) == 0 && t.prefix != `` {
+		prefixes = append(prefixes, string(t.prefix))
+	}
+	if len(prefixes) > 0 {
+		args = append(args, "PREFIX", len(prefixes))
+		for _, prefix := range prefixes {
+			args = append(args, prefix)
+		}
+	}
+	if options.Filter != "" {
+		args = append(args, "FILTER", options.Filter)
+	}
+	if options.Language != "" {
+		args = append(args, "LANGUAGE", options.Language)
+	}
+	if options.StopWords != nil {
+		args = append(args, "STOPWORDS", len(options.StopWords))
+		for _, word := range options.StopWords {
+			args = append(args, word)
+		}
+	}
+	args = append(args, "SCHEMA")
+	for _, field := range fields {
+		fieldArgs, err := field.args()
+		if err != nil {
+			return errors.WithMessagef(err, "<index: %s> <field: %s>", index, field.Name)
+		}
+		args = append(args, fieldArgs...)
+	}
+	if err := t.db.Do(t.ctx("createindex"), args...).Err(); err != nil {
+		return t.wrapErr(err, "<index: %s>", index)
+	}
+	return nil
+}
+
+// 删除索引，deleteDocs 为 true 时同时删除索引的哈希表。返回索引是否存在
+func (t *SearchType) DropIndex(index string, deleteDocs bool) (bool, error) {
+	index = t.prefix.key(index)
+	args := []any{"FT.DROPINDEX", index}
+	if deleteDocs {
+		args = append(args, "DD")
+	}
+	if err := t.db.Do(t.ctx("dropindex"), args...).Err(); err != nil {
+		message := strings.ToLower(err.Error())
+		if strings.Contains(message, "unknown index name") || strings.Contains(message, "no such index") {
+			return false, nil
+		}
+		return false, t.wrapErr(err, "<index: %s>", index)
+	}
+	return true, nil
+}
+
+// 列出 t 的前缀下的所有索引，返回的索引名去掉了前缀
+func (t *SearchType) Indexes() ([]string, error) {
+	indexes, err := t.db.Do(t.ctx("indexes"), "FT._LIST").StringSlice()
+	if err != nil {
+		return nil, t.wrapErr(err, "list indexes failed")
+	}
+	results := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		if strings.HasPrefix(index, string(t.prefix)) {
+			results = append(results, t.prefix.strip(index))
+		}
+	}
+	return results, nil
+}
+
+func (t *SearchType) wrapErr(err error, format string, args ...any) error {
+	return wrapModuleErr(err, "ft.", ErrSearchModuleNotLoaded, format, args...)
+}
+
+func (t *SearchType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "search."+operation)
+}
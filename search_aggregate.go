@@ -0,0 +1,202 @@
+package go_redis
+
+import (
+	"github.com/pkg/errors"
+)
+
+// FT.AGGREGATE 命令构造器。GroupBy、Apply、Filter、SortBy、Limit 按调用顺序组成管道，字段名要带 @
+//
+//	rows, err := instance.Search.Aggregate("products", "*").
+//		GroupBy([]string{"@category"}, go_redis.ReduceCount("count"), go_redis.ReduceAvg("@price", "avg_price")).
+//		Filter("@count > 1").
+//		SortBy(10, go_redis.AggregateSort{Property: "@count"}).
+//		Exec()
+type AggregateQuery struct {
+	t       *SearchType
+	index   string
+	query   string
+	load    []any
+	steps   []any
+	params  []any
+	dialect int
+	err     error
+}
+
+// GroupBy 的聚合函数
+type Reducer struct {
+	Function string // COUNT、SUM、AVG 等
+	Args     []string
+	As       string // 结果的字段名
+}
+
+func ReduceCount(as string) Reducer {
+	return Reducer{Function: "COUNT", As: as}
+}
+
+func ReduceCountDistinct(property string, as string) Reducer {
+	return Reducer{Function: "COUNT_DISTINCT", Args: []string{property}, As: as}
+}
+
+func ReduceSum(property string, as string) Reducer {
+	return Reducer{Function: "SUM", Args: []string{property}, As: as}
+}
+
+func ReduceMin(property string, as string) Reducer {
+	return Reducer{Function: "MIN", Args: []string{property}, As: as}
+}
+
+func ReduceMax(property string, as string) Reducer {
+	return Reducer{Function: "MAX", Args: []string{property}, As: as}
+}
+
+func ReduceAvg(property string, as string) Reducer {
+	return Reducer{Function: "AVG", Args: []string{property}, As: as}
+}
+
+func ReduceToList(property string, as string) Reducer {
+	return Reducer{Function: "TOLIST", Args: []string{property}, As: as}
+}
+
+type AggregateSort struct {
+	Property string // 带 @
+	Asc      bool
+}
+
+func (t *SearchType) Aggregate(index string, query string) *AggregateQuery {
+	return &AggregateQuery{
+		t:     t,
+		index: index,
+		query: query,
+	}
+}
+
+// 从哈希表加载没有 SORTABLE 的字段，字段名带 @
+func (q *AggregateQuery) Load(fields ...string) *AggregateQuery {
+	q.load = []any{"LOAD", len(fields)}
+	for _, field := range fields {
+		q.load = append(q.load, field)
+	}
+	return q
+}
+
+func (q *AggregateQuery) GroupBy(properties []string, reducers ...Reducer) *AggregateQuery {
+	q.steps = append(q.steps, "GROUPBY", len(properties))
+	for _, property := range properties {
+		q.steps = append(q.steps, property)
+	}
+	for _, reducer := range reducers {
+		if reducer.Function == "" {
+			q.err = errors.New("reducer function is empty.")
+			return q
+		}
+		q.steps = append(q.steps, "REDUCE", reducer.Function, len(reducer.Args))
+		for _, arg := range reducer.Args {
+			q.steps = append(q.steps, arg)
+		}
+		if reducer.As != "" {
+			q.steps = append(q.steps, "AS", reducer.As)
+		}
+	}
+	return q
+}
+
+// 用表达式计算新字段，例如 Apply("@price * @quantity", "total")
+func (q *AggregateQuery) Apply(expression string, as string) *AggregateQuery {
+	q.steps = append(q.steps, "APPLY", expression, "AS", as)
+	return q
+}
+
+// 按表达式过滤，例如 Filter("@count > 1")
+func (q *AggregateQuery) Filter(expression string) *AggregateQuery {
+	q.steps = append(q.steps, "FILTER", expression)
+	return q
+}
+
+// 排序，max 大于 0 时只保留前 max 个
+func (q *AggregateQuery) SortBy(max int, sorts ...AggregateSort) *AggregateQuery {
+	q.steps = append(q.steps, "SORTBY", len(sorts)*2)
+	for _, sort := range sorts {
+		order := "DESC"
+		if sort.Asc {
+			order = "ASC"
+		}
+		q.steps = append(q.steps, sort.Property, order)
+	}
+	if max > 0 {
+		q.steps = append(q.steps, "MAX", max)
+	}
+	return q
+}
+
+func (q *AggregateQuery) Limit(offset int, num int) *AggregateQuery {
+	if offset < 0 || num < 0 {
+		q.err = errors.Errorf("<offset: %d> <num: %d> must not be negative.", offset, num)
+		return q
+	}
+	q.steps = append(q.steps, "LIMIT", offset, num)
+	return q
+}
+
+// 查询参数，查询语法中用 $name 引用。会自动使用 DIALECT 2
+func (q *AggregateQuery) Param(name string, value any) *AggregateQuery {
+	q.params = append(q.params, name, value)
+	return q
+}
+
+func (q *AggregateQuery) Dialect(dialect int) *AggregateQuery {
+	q.dialect = dialect
+	return q
+}
+
+// 返回结果行，每行是字段名到值的映射
+func (q *AggregateQuery) Exec() ([]map[string]string, error) {
+	index := q.t.prefix.key(q.index)
+	if q.err != nil {
+		return nil, errors.WithMessagef(q.err, "<index: %s>", index)
+	}
+	args := []any{"FT.AGGREGATE", index, q.query}
+	args = append(args, q.load...)
+	args = append(args, q.steps...)
+	args = appendSearchParams(args, q.params, q.dialect)
+	reply, err := q.t.db.Do(q.t.ctx("aggregate"), args...).Result()
+	if err != nil {
+		return nil, q.t.wrapErr(err, "<index: %s> <query: %s>", index, q.query)
+	}
+	rows, err := parseAggregateReply(reply)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "<index: %s> <query: %s>", index, q.query)
+	}
+	return rows, nil
+}
+
+// RESP2 是 [总数, [字段, 值...], ...]，RESP3 是 {total_results, results: [{extra_attributes}]}
+func parseAggregateReply(reply any) ([]map[string]string, error) {
+	switch reply := reply.(type) {
+	case []any:
+		rows := make([]map[string]string, 0, len(reply))
+		for i := 1; i < len(reply); i++ {
+			row, err := searchPairs(reply[i])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "<row: %d>", i-1)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case map[any]any:
+		items, _ := reply["results"].([]any)
+		rows := make([]map[string]string, 0, len(items))
+		for i, item := range items {
+			entry, ok := item.(map[any]any)
+			if !ok {
+				return nil, errors.Errorf("unexpected aggregate result %T.", item)
+			}
+			row, err := searchPairs(entry["extra_attributes"])
+			if err != nil {
+				return nil, errors.WithMessagef(err, "<row: %d>", i)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+	return nil, errors.Errorf("unexpected aggregate reply %T.", reply)
+}
@@ -0,0 +1,350 @@
+package go_redis
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// FT.SEARCH 命令构造器，query 是 RediSearch 的查询语法，例如 "@title:phone @price:[100 500]"、"*"
+//
+//	result, err := instance.Search.Query("products", "@category:{phone}").
+//		Filter("price", 100, 500).
+//		SortBy("price", true).
+//		Limit(0, 20).
+//		Highlight([]string{"title"}, "<b>", "</b>").
+//		Exec()
+//	products, err := go_redis.DecodeSearchDocuments[Product](result.Docs)
+type SearchQuery struct {
+	t          *SearchType
+	index      string
+	query      string
+	noContent  bool
+	withScores bool
+	filters    []any
+	returns    []string
+	highlight  []any
+	sortBy     []any
+	limit      []any
+	params     []any
+	dialect    int
+	err        error
+}
+
+type SearchResult struct {
+	Total int64 // 匹配的总数，不受 Limit 影响
+	Docs  []SearchDocument
+}
+
+type SearchDocument struct {
+	Id     string  // 哈希表的 key，去掉了 t 的前缀
+	Score  float64 // WithScores 时有值
+	Fields map[string]string
+}
+
+func (t *SearchType) Query(index string, query string) *SearchQuery {
+	return &SearchQuery{
+		t:     t,
+		index: index,
+		query: query,
+	}
+}
+
+func (q *SearchQuery) setErr(err error) *SearchQuery {
+	if q.err == nil {
+		q.err = err
+	}
+	return q
+}
+
+// 只返回 key，不返回字段
+func (q *SearchQuery) NoContent() *SearchQuery {
+	q.noContent = true
+	return q
+}
+
+// 返回相关性得分
+func (q *SearchQuery) WithScores() *SearchQuery {
+	q.withScores = true
+	return q
+}
+
+// NUMERIC 字段的范围过滤，包含 min 和 max，可以用 math.Inf 表示不限
+func (q *SearchQuery) Filter(field string, min float64, max float64) *SearchQuery {
+	q.filters = append(q.filters, "FILTER", field, formatSearchBound(min), formatSearchBound(max))
+	return q
+}
+
+// GEO 字段的范围过滤
+func (q *SearchQuery) GeoFilter(field string, longitude float64, latitude float64, radius float64, unit GeoUnit) *SearchQuery {
+	if err := unit.validate(); err != nil {
+		return q.setErr(err)
+	}
+	q.filters = append(q.filters, "GEOFILTER", field, longitude, latitude, radius, string(unit))
+	return q
+}
+
+func formatSearchBound(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// 只返回这些字段
+func (q *SearchQuery) Return(fields ...string) *SearchQuery {
+	q.returns = append(q.returns, fields...)
+	return q
+}
+
+// 用 open、close 包住匹配的词，fields 为空时处理所有返回的 TEXT 字段
+func (q *SearchQuery) Highlight(fields []string, open string, close string) *SearchQuery {
+	q.highlight = []any{"HIGHLIGHT"}
+	if len(fields) > 0 {
+		q.highlight = append(q.highlight, "FIELDS", len(fields))
+		for _, field := range fields {
+			q.highlight = append(q.highlight, field)
+		}
+	}
+	if open != "" || close != "" {
+		q.highlight = append(q.highlight, "TAGS", open, close)
+	}
+	return q
+}
+
+// 按字段排序，字段需要是 SORTABLE 的
+func (q *SearchQuery) SortBy(field string, asc bool) *SearchQuery {
+	order := "DESC"
+	if asc {
+		order = "ASC"
+	}
+	q.sortBy = []any{"SORTBY", field, order}
+	return q
+}
+
+// 分页，默认 0 10
+func (q *SearchQuery) Limit(offset int, num int) *SearchQuery {
+	if offset < 0 || num < 0 {
+		return q.setErr(errors.Errorf("<offset: %d> <num: %d> must not be negative.", offset, num))
+	}
+	q.limit = []any{"LIMIT", offset, num}
+	return q
+}
+
+// 查询参数，查询语法中用 $name 引用。会自动使用 DIALECT 2
+func (q *SearchQuery) Param(name string, value any) *SearchQuery {
+	q.params = append(q.params, name, value)
+	return q
+}
+
+// 查询语法的版本
+func (q *SearchQuery) Dialect(dialect int) *SearchQuery {
+	q.dialect = dialect
+	return q
+}
+
+func (q *SearchQuery) args(index string) []any {
+	args := []any{"FT.SEARCH", index, q.query}
+	if q.noContent {
+		args = append(args, "NOCONTENT")
+	}
+	if q.withScores {
+		args = append(args, "WITHSCORES")
+	}
+	args = append(args, q.filters...)
+	if len(q.returns) > 0 {
+		args = append(args, "RETURN", len(q.returns))
+		for _, field := range q.returns {
+			args = append(args, field)
+		}
+	}
+	args = append(args, q.highlight...)
+	args = append(args, q.sortBy...)
+	args = append(args, q.limit...)
+	return appendSearchParams(args, q.params, q.dialect)
+}
+
+func appendSearchParams(args []any, params []any, dialect int) []any {
+	if len(params) > 0 {
+		args = append(args, "PARAMS", len(params))
+		args = append(args, params...)
+		if dialect == 0 {
+			dialect = 2
+		}
+	}
+	if dialect > 0 {
+		args = append(args, "DIALECT", dialect)
+	}
+	return args
+}
+
+func (q *SearchQuery) Exec() (*SearchResult, error) {
+	index := q.t.prefix.key(q.index)
+	if q.err != nil {
+		return nil, errors.WithMessagef(q.err, "<index: %s>", index)
+	}
+	reply, err := q.t.db.Do(q.t.ctx("query"), q.args(index)...).Result()
+	if err != nil {
+		return nil, q.t.wrapErr(err, "<index: %s> <query: %s>", index, q.query)
+	}
+	result, err := parseSearchReply(reply, q.withScores, q.noContent)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "<index: %s> <query: %s>", index, q.query)
+	}
+	for i := range result.Docs {
+		result.Docs[i].Id = q.t.prefix.strip(result.Docs[i].Id)
+	}
+	return result, nil
+}
+
+// RESP2 是 [总数, key, [分数], [字段, 值...], ...]，RESP3 是 {total_results, results: [{id, score, extra_attributes}]}
+func parseSearchReply(reply any, withScores bool, noContent bool) (*SearchResult, error) {
+	switch reply := reply.(type) {
+	case []any:
+		if len(reply) == 0 {
+			return nil, errors.New("empty search reply.")
+		}
+		total, ok := reply[0].(int64)
+		if !ok {
+			return nil, errors.Errorf("unexpected search total %T.", reply[0])
+		}
+		result := &SearchResult{Total: total}
+		for i := 1; i < len(reply); {
+			var doc SearchDocument
+			doc.Id = searchString(reply[i])
+			i++
+			if withScores && i < len(reply) {
+				score, err := searchFloat(reply[i])
+				if err != nil {
+					return nil, errors.WithMessagef(err, "<id: %s> score", doc.Id)
+				}
+				doc.Score = score
+				i++
+			}
+			if !noContent && i < len(reply) {
+				fields, err := searchPairs(reply[i])
+				if err != nil {
+					return nil, errors.WithMessagef(err, "<id: %s>", doc.Id)
+				}
+				doc.Fields = fields
+				i++
+			}
+			result.Docs = append(result.Docs, doc)
+		}
+		return result, nil
+	case map[any]any:
+		total, _ := reply["total_results"].(int64)
+		result := &SearchResult{Total: total}
+		items, _ := reply["results"].([]any)
+		for _, item := range items {
+			entry, ok := item.(map[any]any)
+			if !ok {
+				return nil, errors.Errorf("unexpected search result %T.", item)
+			}
+			doc := SearchDocument{Id: searchString(entry["id"])}
+			if score, ok := entry["score"]; ok {
+				value, err := searchFloat(score)
+				if err != nil {
+					return nil, errors.WithMessagef(err, "<id: %s> score", doc.Id)
+				}
+				doc.Score = value
+			}
+			if attributes, ok := entry["extra_attributes"]; ok {
+				fields, err := searchPairs(attributes)
+				if err != nil {
+					return nil, errors.WithMessagef(err, "<id: %s>", doc.Id)
+				}
+				doc.Fields = fields
+			}
+			result.Docs = append(result.Docs, doc)
+		}
+		return result, nil
+	}
+	return nil, errors.Errorf("unexpected search reply %T.", reply)
+}
+
+// 字段值对，RESP2 是 [字段, 值...]，RESP3 是 map
+func searchPairs(value any) (map[string]string, error) {
+	switch value := value.(type) {
+	case []any:
+		if len(value)%2 != 0 {
+			return nil, errors.Errorf("odd number of field values: %d.", len(value))
+		}
+		fields := make(map[string]string, len(value)/2)
+		for i := 0; i < len(value); i += 2 {
+			fields[searchString(value[i])] = searchString(value[i+1])
+		}
+		return fields, nil
+	case map[any]any:
+		fields := make(map[string]string, len(value))
+		for k, v := range value {
+			fields[searchString(k)] = searchString(v)
+		}
+		return fields, nil
+	}
+	return nil, errors.Errorf("unexpected fields %T.", value)
+}
+
+func searchString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func searchFloat(value any) (float64, error) {
+	switch value := value.(type) {
+	case float64:
+		return value, nil
+	case int64:
+		return float64(value), nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	}
+	return 0, errors.Errorf("unexpected number %T.", value)
+}
+
+// 把字段解码到结构体，字段名取 `redis` 标签，和 GetStruct 一致
+func (d *SearchDocument) Decode(dst any) error {
+	rv, err := structPointerValueOf(dst)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", d.Id)
+	}
+	return decodeStructFields(rv, d.Fields, "key: "+d.Id)
+}
+
+// 把查询结果解码成结构体
+func DecodeSearchDocuments[T any](docs []SearchDocument) ([]T, error) {
+	results := make([]T, len(docs))
+	for i := range docs {
+		if err := docs[i].Decode(&results[i]); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// 把 FT.AGGREGATE 的结果行解码成结构体，结果行没有 key，错误信息中是行号，例如 <row: 1, field: total>
+func DecodeAggregateRows[T any](rows []map[string]string) ([]T, error) {
+	results := make([]T, len(rows))
+	for i, row := range rows {
+		rv, err := structPointerValueOf(&results[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeStructFields(rv, row, "row: "+strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
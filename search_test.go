@@ -0,0 +1,452 @@
+package go_redis
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	go_test_ "github.com/pefish/go-test"
+	"github.com/pkg/errors"
+)
+
+type searchProduct struct {
+	Title    string   `redis:"title" search:"text,weight=2,sortable"`
+	Category string   `redis:"category" search:"tag,separator=|"`
+	Price    float64  `redis:"price" search:"numeric,sortable"`
+	Location string   `redis:"location" search:"geo"`
+	Tags     []string `redis:"tags"`
+}
+
+var fakeKNNRegexp = regexp.MustCompile(`^(?:\*|\(@(\w+):\{(\w+)\}\))=>\[KNN \$(\w+) @(\w+) \$(\w+)(?: EF_RUNTIME \d+)? AS (\w+)\]$`)
+
+type fakeSearch struct {
+	mu       sync.Mutex
+	indexes  map[string][]string // 索引名 → key 前缀
+	received map[string][]string // 命令 → 最近一次的参数
+}
+
+// miniredis 不支持 RediSearch，这里注册一个最小实现：FT.SEARCH 忽略查询语法，按 key 排序返回索引前缀下的所有哈希表，
+// 支持 NOCONTENT、WITHSCORES（得分都是 1）、RETURN 以及带 TAG 预过滤的 KNN 查询；FT.AGGREGATE 返回固定的两行。回复格式按连接的协议版本
+func registerFakeSearch(t *testing.T, m *miniredis.Miniredis) *fakeSearch {
+	fake := &fakeSearch{
+		indexes:  make(map[string][]string),
+		received: make(map[string][]string),
+	}
+	srv := m.Server()
+	register := func(name string, handler func(c *server.Peer, args []string)) {
+		go_test_.Equal(t, nil, srv.Register(name, func(c *server.Peer, cmd string, args []string) {
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			fake.received[name] = args
+			handler(c, args)
+		}))
+	}
+	register("FT.CREATE", func(c *server.Peer, args []string) {
+		if _, ok := fake.indexes[args[0]]; ok {
+			c.WriteError("Index already exists")
+			return
+		}
+		prefixes := []string{""}
+		for i, arg := range args {
+			if arg == "PREFIX" {
+				n, _ := strconv.Atoi(args[i+1])
+				prefixes = args[i+2 : i+2+n]
+			}
+		}
+		fake.indexes[args[0]] = prefixes
+		c.WriteOK()
+	})
+	register("FT.DROPINDEX", func(c *server.Peer, args []string) {
+		if _, ok := fake.indexes[args[0]]; !ok {
+			c.WriteError("Unknown Index name")
+			return
+		}
+		delete(fake.indexes, args[0])
+		c.WriteOK()
+	})
+	register("FT._LIST", func(c *server.Peer, args []string) {
+		names := make([]string, 0, len(fake.indexes))
+		for name := range fake.indexes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		c.WriteStrings(names)
+	})
+	register("FT.SEARCH", func(c *server.Peer, args []string) {
+		prefixes, ok := fake.indexes[args[0]]
+		if !ok {
+			c.WriteError("No such index " + args[0])
+			return
+		}
+		var noContent, withScores bool
+		var returns []string
+		params := make(map[string]string)
+		for i, arg := range args {
+			switch arg {
+			case "NOCONTENT":
+				noContent = true
+			case "WITHSCORES":
+				withScores = true
+			case "RETURN":
+				n, _ := strconv.Atoi(args[i+1])
+				returns = args[i+2 : i+2+n]
+			case "PARAMS":
+				n, _ := strconv.Atoi(args[i+1])
+				for j := i + 2; j < i+2+n; j += 2 {
+					params[args[j]] = args[j+1]
+				}
+			}
+		}
+		keys := make([]string, 0)
+		for _, key := range m.Keys() {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(key, prefix) && m.Type(key) == "hash" {
+					keys = append(keys, key)
+					break
+				}
+			}
+		}
+		// KNN 查询：按 TAG 预过滤，再按 L2 距离的平方排序取前 k 个
+		distances := make(map[string]string)
+		var distanceAs string
+		if matches := fakeKNNRegexp.FindStringSubmatch(args[1]); matches != nil {
+			k, _ := strconv.Atoi(params[matches[3]])
+			vector, _ := DecodeFloat32Vector([]byte(params[matches[5]]))
+			distanceAs = matches[6]
+			type candidate struct {
+				key      string
+				distance float64
+			}
+			candidates := make([]candidate, 0)
+			for _, key := range keys {
+				if matches[1] != "" && m.HGet(key, matches[1]) != matches[2] {
+					continue
+				}
+				stored, err := DecodeFloat32Vector([]byte(m.HGet(key, matches[4])))
+				if err != nil || len(stored) != len(vector) {
+					continue
+				}
+				var distance float64
+				for i := range vector {
+					diff := float64(vector[i] - stored[i])
+					distance += diff * diff
+				}
+				candidates = append(candidates, candidate{key, distance})
+			}
+			sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+			if len(candidates) > k {
+				candidates = candidates[:k]
+			}
+			keys = keys[:0]
+			for _, candidate := range candidates {
+				keys = append(keys, candidate.key)
+				distances[candidate.key] = strconv.FormatFloat(candidate.distance, 'f', -1, 64)
+			}
+		}
+		fields := func(key string) []string {
+			names := returns
+			if names == nil {
+				names, _ = m.HKeys(key)
+			}
+			pairs := make([]string, 0, len(names)*2)
+			for _, name := range names {
+				if name == distanceAs {
+					pairs = append(pairs, name, distances[key])
+					continue
+				}
+				pairs = append(pairs, name, m.HGet(key, name))
+			}
+			return pairs
+		}
+		if c.Resp3 {
+			c.WriteMapLen(2)
+			c.WriteBulk("total_results")
+			c.WriteInt(len(keys))
+			c.WriteBulk("results")
+			c.WriteLen(len(keys))
+			for _, key := range keys {
+				n := 1
+				if withScores {
+					n++
+				}
+				if !noContent {
+					n++
+				}
+				c.WriteMapLen(n)
+				c.WriteBulk("id")
+				c.WriteBulk(key)
+				if withScores {
+					c.WriteBulk("score")
+					c.WriteFloat(1)
+				}
+				if !noContent {
+					pairs := fields(key)
+					c.WriteBulk("extra_attributes")
+					c.WriteMapLen(len(pairs) / 2)
+					for _, s := range pairs {
+						c.WriteBulk(s)
+					}
+				}
+			}
+			return
+		}
+		n := 1 + len(keys)
+		if withScores {
+			n += len(keys)
+		}
+		if !noContent {
+			n += len(keys)
+		}
+		c.WriteLen(n)
+		c.WriteInt(len(keys))
+		for _, key := range keys {
+			c.WriteBulk(key)
+			if withScores {
+				c.WriteBulk("1")
+			}
+			if !noContent {
+				c.WriteStrings(fields(key))
+			}
+		}
+	})
+	register("FT.AGGREGATE", func(c *server.Peer, args []string) {
+		rows := [][]string{
+			{"category", "phone", "count", "2", "avg_price", "550"},
+			{"category", "laptop", "count", "1", "avg_price", "1200"},
+		}
+		if c.Resp3 {
+			c.WriteMapLen(2)
+			c.WriteBulk("total_results")
+			c.WriteInt(len(rows))
+			c.WriteBulk("results")
+			c.WriteLen(len(rows))
+			for _, row := range rows {
+				c.WriteMapLen(1)
+				c.WriteBulk("extra_attributes")
+				c.WriteMapLen(len(row) / 2)
+				for _, s := range row {
+					c.WriteBulk(s)
+				}
+			}
+			return
+		}
+		c.WriteLen(len(rows) + 1)
+		c.WriteInt(len(rows))
+		for _, row := range rows {
+			c.WriteStrings(row)
+		}
+	})
+	return fake
+}
+
+func (f *fakeSearch) args(command string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.received[command], " ")
+}
+
+func TestSearchSchema(t *testing.T) {
+	fields, err := SearchSchema(&searchProduct{})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []SearchField{
+		{Name: "title", Type: SearchText, Weight: 2, Sortable: true},
+		{Name: "category", Type: SearchTag, Separator: "|"},
+		{Name: "price", Type: SearchNumeric, Sortable: true},
+		{Name: "location", Type: SearchGeo},
+	}, fields)
+
+	fields, err = SearchSchema(struct {
+		Embedding []byte `redis:"embedding" search:"vector,algorithm=hnsw,dim=4,distance=l2"`
+	}{})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, &VectorFieldOptions{Algorithm: VectorHNSW, Dim: 4, Distance: VectorL2}, fields[0].Vector)
+
+	_, err = SearchSchema(struct {
+		Title string `redis:"title" search:"text,dim=4"`
+	}{})
+	go_test_.NotEqual(t, nil, err)
+	_, err = SearchSchema(struct {
+		Title string `redis:"title" search:"text,bogus"`
+	}{})
+	go_test_.NotEqual(t, nil, err)
+	_, err = SearchSchema(struct {
+		Title string `redis:"title"`
+	}{})
+	go_test_.NotEqual(t, nil, err)
+	_, err = SearchSchema("products")
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestSearchType_Index(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	fake := registerFakeSearch(t, m)
+	shop := instance.WithPrefix("shop:")
+
+	go_test_.Equal(t, nil, shop.Search.CreateIndex("products", searchProduct{}, &IndexOptions{
+		Prefixes:  []string{"product:"},
+		StopWords: []string{},
+	}))
+	go_test_.Equal(t, "shop:products ON HASH PREFIX 1 shop:product: STOPWORDS 0 SCHEMA "+
+		"title TEXT WEIGHT 2 SORTABLE category TAG SEPARATOR | price NUMERIC SORTABLE location GEO", fake.args("FT.CREATE"))
+	go_test_.Equal(t, true, shop.Search.CreateIndex("products", searchProduct{}, nil) != nil)
+
+	// 没有指定前缀时使用视图的前缀
+	go_test_.Equal(t, nil, shop.Search.CreateIndex("vectors", []SearchField{
+		{Name: "embedding", Type: SearchVector, Vector: &VectorFieldOptions{Dim: 3}},
+	}, &IndexOptions{Filter: "@price > 0", Language: "english"}))
+	go_test_.Equal(t, "shop:vectors ON HASH PREFIX 1 shop: FILTER @price > 0 LANGUAGE english SCHEMA "+
+		"embedding VECTOR FLAT 6 TYPE FLOAT32 DIM 3 DISTANCE_METRIC COSINE", fake.args("FT.CREATE"))
+	go_test_.Equal(t, true, shop.Search.CreateIndex("bad", []SearchField{{Name: "embedding", Type: SearchVector}}, nil) != nil)
+
+	go_test_.Equal(t, nil, instance.Search.CreateIndex("all", searchProduct{}, nil))
+	go_test_.Equal(t, "all ON HASH SCHEMA "+
+		"title TEXT WEIGHT 2 SORTABLE category TAG SEPARATOR | price NUMERIC SORTABLE location GEO", fake.args("FT.CREATE"))
+
+	indexes, err := shop.Search.Indexes()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []string{"products", "vectors"}, indexes)
+
+	dropped, err := shop.Search.DropIndex("vectors", true)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, true, dropped)
+	go_test_.Equal(t, "shop:vectors DD", fake.args("FT.DROPINDEX"))
+	dropped, err = shop.Search.DropIndex("vectors", false)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, dropped)
+}
+
+func TestSearchType_Query(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	fake := registerFakeSearch(t, m)
+	shop := instance.WithPrefix("shop:")
+	go_test_.Equal(t, nil, shop.Search.CreateIndex("products", searchProduct{}, &IndexOptions{Prefixes: []string{"product:"}}))
+	go_test_.Equal(t, nil, shop.Hash.SetStruct("product:1", searchProduct{Title: "phone x", Category: "phone", Price: 500, Tags: []string{"new"}}))
+	go_test_.Equal(t, nil, shop.Hash.SetStruct("product:2", searchProduct{Title: "phone y", Category: "phone", Price: 600}))
+	go_test_.Equal(t, nil, shop.Hash.Set("other:1", "title", "not indexed"))
+
+	result, err := shop.Search.Query("products", "@category:{phone}").
+		WithScores().
+		Filter("price", 100, math.Inf(1)).
+		GeoFilter("location", 116.4, 39.9, 10, GeoKilometers).
+		Highlight([]string{"title"}, "<b>", "</b>").
+		SortBy("price", true).
+		Limit(0, 20).
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "shop:products @category:{phone} WITHSCORES FILTER price 100 +inf GEOFILTER location 116.4 39.9 10 km "+
+		"HIGHLIGHT FIELDS 1 title TAGS <b> </b> SORTBY price ASC LIMIT 0 20", fake.args("FT.SEARCH"))
+	go_test_.Equal(t, int64(2), result.Total)
+	go_test_.Equal(t, "product:1", result.Docs[0].Id)
+	go_test_.Equal(t, float64(1), result.Docs[0].Score)
+	products, err := DecodeSearchDocuments[searchProduct](result.Docs)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []searchProduct{
+		{Title: "phone x", Category: "phone", Price: 500, Tags: []string{"new"}},
+		{Title: "phone y", Category: "phone", Price: 600},
+	}, products)
+
+	result, err = shop.Search.Query("products", "@price:[$min +inf]").
+		Return("title").
+		Param("min", 550).
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "shop:products @price:[$min +inf] RETURN 1 title PARAMS 2 min 550 DIALECT 2", fake.args("FT.SEARCH"))
+	go_test_.Equal(t, map[string]string{"title": "phone y"}, result.Docs[1].Fields)
+
+	result, err = shop.Search.Query("products", "*").NoContent().Dialect(3).Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "shop:products * NOCONTENT DIALECT 3", fake.args("FT.SEARCH"))
+	go_test_.Equal(t, []SearchDocument{{Id: "product:1"}, {Id: "product:2"}}, result.Docs)
+
+	_, err = shop.Search.Query("products", "*").Limit(-1, 10).Exec()
+	go_test_.NotEqual(t, nil, err)
+	_, err = shop.Search.Query("products", "*").GeoFilter("location", 0, 0, 1, "yd").Exec()
+	go_test_.NotEqual(t, nil, err)
+	_, err = shop.Search.Query("missing", "*").Exec()
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, false, errors.Is(err, ErrSearchModuleNotLoaded))
+
+	go_test_.Equal(t, nil, shop.Hash.Set("product:3", "price", "cheap"))
+	result, err = shop.Search.Query("products", "*").Exec()
+	go_test_.Equal(t, nil, err)
+	_, err = DecodeSearchDocuments[searchProduct](result.Docs)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: product:3, field: price>"))
+}
+
+func TestSearchType_Aggregate(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	fake := registerFakeSearch(t, m)
+
+	rows, err := instance.Search.Aggregate("products", "@price:[$min +inf]").
+		Load("@title").
+		GroupBy([]string{"@category"}, ReduceCount("count"), ReduceAvg("@price", "avg_price")).
+		Apply("@avg_price * 2", "double").
+		Filter("@count > 0").
+		SortBy(10, AggregateSort{Property: "@count"}, AggregateSort{Property: "@category", Asc: true}).
+		Limit(0, 5).
+		Param("min", 0).
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "products @price:[$min +inf] LOAD 1 @title GROUPBY 1 @category REDUCE COUNT 0 AS count "+
+		"REDUCE AVG 1 @price AS avg_price APPLY @avg_price * 2 AS double FILTER @count > 0 "+
+		"SORTBY 4 @count DESC @category ASC MAX 10 LIMIT 0 5 PARAMS 2 min 0 DIALECT 2", fake.args("FT.AGGREGATE"))
+	go_test_.Equal(t, []map[string]string{
+		{"category": "phone", "count": "2", "avg_price": "550"},
+		{"category": "laptop", "count": "1", "avg_price": "1200"},
+	}, rows)
+
+	type categoryStats struct {
+		Category string  `redis:"category"`
+		Count    int     `redis:"count"`
+		AvgPrice float64 `redis:"avg_price"`
+	}
+	stats, err := DecodeAggregateRows[categoryStats](rows)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, categoryStats{Category: "laptop", Count: 1, AvgPrice: 1200}, stats[1])
+	_, err = DecodeAggregateRows[categoryStats]([]map[string]string{{"count": "x"}})
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<row: 0, field: count> string <x> to int failed."))
+
+	_, err = instance.Search.Aggregate("products", "*").GroupBy([]string{"@category"}, Reducer{}).Exec()
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestParseSearchReply_Resp2(t *testing.T) {
+	result, err := parseSearchReply([]any{
+		int64(5),
+		"product:1", "1.5", []any{"title", "phone"},
+		"product:2", "0.5", []any{"title", "laptop"},
+	}, true, false)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, &SearchResult{Total: 5, Docs: []SearchDocument{
+		{Id: "product:1", Score: 1.5, Fields: map[string]string{"title": "phone"}},
+		{Id: "product:2", Score: 0.5, Fields: map[string]string{"title": "laptop"}},
+	}}, result)
+
+	result, err = parseSearchReply([]any{int64(1), "product:1"}, false, true)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []SearchDocument{{Id: "product:1"}}, result.Docs)
+
+	_, err = parseSearchReply([]any{int64(1), "product:1", []any{"title"}}, false, false)
+	go_test_.NotEqual(t, nil, err)
+
+	rows, err := parseAggregateReply([]any{int64(1), []any{"category", "phone", "count", int64(2)}})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []map[string]string{{"category": "phone", "count": "2"}}, rows)
+}
+
+func TestSearchType_ModuleNotLoaded(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	err := instance.Search.CreateIndex("products", searchProduct{}, nil)
+	go_test_.Equal(t, true, errors.Is(err, ErrSearchModuleNotLoaded))
+	_, err = instance.Search.Query("products", "*").Exec()
+	go_test_.Equal(t, true, errors.Is(err, ErrSearchModuleNotLoaded))
+	_, err = instance.Search.Aggregate("products", "*").Exec()
+	go_test_.Equal(t, true, errors.Is(err, ErrSearchModuleNotLoaded))
+}
@@ -2,6 +2,7 @@ package go_redis
 
 import (
 	"context"
+	"sync/atomic"
 
 	i_logger "github.com/pefish/go-interface/i-logger"
 	"github.com/pkg/errors"
@@ -9,22 +10,25 @@ import (
 )
 
 type SetType struct {
-	db     *redis.Client
-	logger i_logger.ILogger
+	db      *redis.Client
+	logger  i_logger.ILogger
+	codec   *atomic.Pointer[valueCodec]
+	prefix  keyPrefix
+	baseCtx context.Context
 }
 
 // 向集合添加一个或多个成员
 func (t *SetType) Add(key string, member string) error {
-	t.logger.DebugF(`Redis sadd. key: %s, member: %s`, key, member)
-	if err := t.db.SAdd(context.Background(), key, member).Err(); err != nil {
+	key = t.prefix.key(key)
+	if err := t.db.SAdd(t.ctx("add"), key, member).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
 }
 
 func (t *SetType) AddBatch(key string, members []any) error {
-	t.logger.DebugF(`Redis sadd. key: %s, members: ...`, key)
-	if err := t.db.SAdd(context.Background(), key, members...).Err(); err != nil {
+	key = t.prefix.key(key)
+	if err := t.db.SAdd(t.ctx("addbatch"), key, members...).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
@@ -32,8 +36,8 @@ func (t *SetType) AddBatch(key string, members []any) error {
 
 // 返回集合中的所有成员，key 不存在时返回 nil,nil
 func (rc *SetType) Members(key string) ([]string, error) {
-	rc.logger.DebugF(`Redis smembers. key: %s`, key)
-	result, err := rc.db.SMembers(context.Background(), key).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.db.SMembers(rc.ctx("members"), key).Result()
 	if err != nil {
 		return nil, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -42,8 +46,8 @@ func (rc *SetType) Members(key string) ([]string, error) {
 
 // 判断 member 元素是否是集合 key 的成员
 func (rc *SetType) IsMember(key string, member string) (bool, error) {
-	rc.logger.DebugF(`Redis sismember. key: %s, member: %s`, key, member)
-	result, err := rc.db.SIsMember(context.Background(), key, member).Result()
+	key = rc.prefix.key(key)
+	result, err := rc.db.SIsMember(rc.ctx("ismember"), key, member).Result()
 	if err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
@@ -52,14 +56,18 @@ func (rc *SetType) IsMember(key string, member string) (bool, error) {
 
 // 移除集合中一个或多个成员
 func (t *SetType) Remove(key string, members ...string) error {
-	t.logger.DebugF(`Redis srem. key: %s, members: %v`, key, members)
+	key = t.prefix.key(key)
 	rawMembers := make([]interface{}, 0)
 	for _, member := range members {
 		rawMembers = append(rawMembers, member)
 	}
-	_, err := t.db.SRem(context.Background(), key, rawMembers...).Result()
+	_, err := t.db.SRem(t.ctx("remove"), key, rawMembers...).Result()
 	if err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
 }
+
+func (t *SetType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "set."+operation)
+}
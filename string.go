@@ -2,7 +2,7 @@ package go_redis
 
 import (
 	"context"
-	"strconv"
+	"sync/atomic"
 	"time"
 
 	i_logger "github.com/pefish/go-interface/i-logger"
@@ -11,84 +11,107 @@ import (
 )
 
 type StringType struct {
-	db     *redis.Client
-	logger i_logger.ILogger
+	db          *redis.Client
+	logger      i_logger.ILogger
+	clientCache *clientCache
+	codec       *atomic.Pointer[valueCodec]
+	prefix      keyPrefix
+	baseCtx     context.Context
 }
 
 // 设置指定 key 的值。
 func (t *StringType) Set(key string, value string, expiration time.Duration) error {
-	t.logger.DebugF(`Redis set. key: %s, val: %s, expiration: %v`, key, value, expiration)
-	if err := t.db.Set(context.Background(), key, value, expiration).Err(); err != nil {
+	key = t.prefix.key(key)
+	if err := t.db.Set(t.ctx("set"), key, value, expiration).Err(); err != nil {
 		return errors.Wrapf(err, "<key: %s>", key)
 	}
 	return nil
 }
 
 func (t *StringType) SetUint64(key string, value uint64, expiration time.Duration) error {
-	t.logger.DebugF(`Redis set. key: %s, val: %s, expiration: %v`, key, value, expiration)
-	if err := t.db.Set(context.Background(), key, value, expiration).Err(); err != nil {
-		return errors.Wrapf(err, "<key: %s>", key)
-	}
-	return nil
+	return SetAs(t, key, value, expiration)
 }
 
 // 只有在 key 不存在时设置 key 的值，设置成功返回 true。
 func (t *StringType) SetNX(key string, value string, expiration time.Duration) (bool, error) {
-	t.logger.DebugF(`Redis setnx. key: %s, val: %s, expiration: %v`, key, value, expiration)
-	result := t.db.SetNX(context.Background(), key, value, expiration)
+	key = t.prefix.key(key)
+	result := t.db.SetNX(t.ctx("setnx"), key, value, expiration)
 	if err := result.Err(); err != nil {
 		return false, errors.Wrapf(err, "<key: %s>", key)
 	}
 	return result.Val(), nil
 }
 
-// 获取指定 key 的值。
+// 获取指定 key 的值。key 不存在时返回空字符串，需要区分的话用 GetOk
 func (t *StringType) Get(key string) (string, error) {
-	t.logger.DebugF(`Redis get. key: %s`, key)
-	result, err := t.db.Get(context.Background(), key).Result()
+	result, _, err := t.GetOk(key)
+	return result, err
+}
+
+// 获取指定 key 的值，found 为 false 表示 key 不存在
+func (t *StringType) GetOk(key string) (result_ string, found_ bool, err_ error) {
+	key = t.prefix.key(key)
+	if t.clientCache != nil {
+		return t.getCached(key)
+	}
+	result, err := t.db.Get(t.ctx("get"), key).Result()
 	if err != nil {
-		if err.Error() == `redis: nil` {
-			return ``, nil
+		if errors.Is(err, redis.Nil) {
+			return ``, false, nil
 		}
-		return ``, errors.Wrapf(err, "<key: %s>", key)
+		return ``, false, errors.Wrapf(err, "<key: %s>", key)
 	}
-	t.logger.DebugF(`Redis get. result: %s`, result)
-	return result, nil
+	return result, true, nil
 }
 
 func (t *StringType) GetUint64(key string) (uint64, error) {
-	t.logger.DebugF(`Redis get. key: %s`, key)
-	result, err := t.db.Get(context.Background(), key).Uint64()
-	if err != nil {
-		if err.Error() == `redis: nil` {
-			return 0, nil
-		}
-		return 0, errors.Wrapf(err, "<key: %s>", key)
-	}
-	t.logger.DebugF(`Redis get. result: %s`, result)
-	return result, nil
+	result, _, err := t.GetUint64Ok(key)
+	return result, err
+}
+
+func (t *StringType) GetUint64Ok(key string) (result_ uint64, found_ bool, err_ error) {
+	return GetAsOk[uint64](t, key)
 }
 
 func (t *StringType) GetFloat64(key string) (float64, error) {
-	resultStr, err := t.Get(key)
-	if err != nil {
-		return 0, err
-	}
-	if resultStr == "" {
-		return 0, nil
-	}
-	r, err := strconv.ParseFloat(resultStr, 64)
-	if err != nil {
-		return 0, errors.Wrapf(err, "<key: %s> string to float64 failed.", key)
-	}
-	return r, nil
+	return GetAs[float64](t, key)
+}
+
+func (t *StringType) GetFloat64Ok(key string) (result_ float64, found_ bool, err_ error) {
+	return GetAsOk[float64](t, key)
 }
 
 func (rc *StringType) IncrBy(key string, increment int64) (int64, error) {
-	rc.logger.DebugF(`Redis IncrBy. key: %s, increment: %f`, key, increment)
-	result := rc.db.IncrBy(context.Background(), key, increment)
+	key = rc.prefix.key(key)
+	result := rc.db.IncrBy(rc.ctx("incrby"), key, increment)
 	if result.Err() != nil {
 		return 0, errors.Wrapf(result.Err(), "<key: %s>", key)
 	}
 	return result.Val(), nil
 }
+
+func (t *StringType) getCached(key string) (string, bool, error) {
+	cacheKey := clientCacheKey("string", key)
+	value, seq, ok := t.clientCache.get(cacheKey)
+	if ok {
+		// nil 表示 key 不存在
+		if value.(*string) == nil {
+			return ``, false, nil
+		}
+		return *value.(*string), true, nil
+	}
+	result, err := t.clientCache.tracking().Get(t.ctx("get"), key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			return ``, false, errors.Wrapf(err, "<key: %s>", key)
+		}
+		t.clientCache.set(cacheKey, key, (*string)(nil), seq)
+		return ``, false, nil
+	}
+	t.clientCache.set(cacheKey, key, &result, seq)
+	return result, true, nil
+}
+
+func (t *StringType) ctx(operation string) context.Context {
+	return withOperation(t.baseCtx, "string."+operation)
+}
@@ -0,0 +1,10 @@
+#!lua name=queue
+-- version: 2
+
+redis.register_function('echo', function(keys, args)
+  return {keys[1], args[1]}
+end)
+
+redis.register_function{function_name='peek', callback=function(keys, args)
+  return redis.call('lindex', keys[1], 0)
+end, flags={'no-writes'}}
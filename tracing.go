@@ -0,0 +1,109 @@
+package go_redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TracingHookName = "tracing"
+
+const tracerName = "github.com/pefish/go-redis"
+
+type TracingOptions struct {
+	TracerProvider trace.TracerProvider    // 默认 otel.GetTracerProvider()
+	WithKeys       bool                    // span 上是否带 key 名（db.redis.keys），默认不带
+	RedactKey      func(key string) string // WithKeys 时对 key 名脱敏，例如把 user:123 变成 user:*
+}
+
+// 开启 OpenTelemetry 链路追踪，每条命令（pipeline 算一条）生成一个 client span。
+// span 的父节点来自调用方的 ctx，需要配合 WithContext 使用：
+//
+//	instance.EnableTracing(nil)
+//	value, err := instance.WithContext(ctx).String.Get("key")
+//
+// span 名为封装方法名（例如 string.get），直接通过 Db 执行的命令为命令名。重复调用会替换之前的配置
+func (t *RedisType) EnableTracing(options *TracingOptions) {
+	if options == nil {
+		options = &TracingOptions{}
+	}
+	provider := options.TracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(tracerName)
+
+	type spanKey struct{}
+	t.RemoveHook(TracingHookName)
+	t.AddHook(Hook{
+		Name: TracingHookName,
+		Before: func(ctx context.Context, info *CommandInfo) context.Context {
+			name := info.Operation
+			if name == `` {
+				name = info.Name
+			}
+			attrs := []attribute.KeyValue{
+				attribute.String("db.system", "redis"),
+				attribute.String("db.operation", strings.ToUpper(info.Name)),
+			}
+			if info.Operation != `` {
+				attrs = append(attrs, attribute.String("db.redis.wrapper", info.Operation))
+			}
+			if t.Db != nil {
+				dbOptions := t.Db.Options()
+				attrs = append(attrs, attribute.Int("db.redis.database_index", dbOptions.DB))
+				attrs = append(attrs, peerAttributes(dbOptions.Addr)...)
+			}
+			if info.Pipeline {
+				attrs = append(attrs, attribute.Int("db.redis.num_cmd", len(info.Cmds)))
+			}
+			if options.WithKeys && len(info.Keys) > 0 {
+				keys := info.Keys
+				if options.RedactKey != nil {
+					keys = make([]string, 0, len(info.Keys))
+					for _, key := range info.Keys {
+						keys = append(keys, options.RedactKey(key))
+					}
+				}
+				attrs = append(attrs, attribute.StringSlice("db.redis.keys", keys))
+			}
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			return context.WithValue(ctx, spanKey{}, span)
+		},
+		After: func(ctx context.Context, event *CommandEvent) {
+			span, ok := ctx.Value(spanKey{}).(trace.Span)
+			if !ok {
+				return
+			}
+			// Before 时还不知道是否会转发到副本，这里改成实际执行命令的副本地址
+			if event.Addr != `` {
+				span.SetAttributes(peerAttributes(event.Addr)...)
+			}
+			if event.Err != nil && !errors.Is(event.Err, redis.Nil) {
+				span.RecordError(event.Err)
+				span.SetStatus(codes.Error, event.Err.Error())
+			}
+			span.End()
+		},
+	})
+}
+
+func peerAttributes(addr string) []attribute.KeyValue {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String("net.peer.name", addr)}
+	}
+	attrs := []attribute.KeyValue{attribute.String("net.peer.name", host)}
+	if port, err := strconv.Atoi(portStr); err == nil {
+		attrs = append(attrs, attribute.Int("net.peer.port", port))
+	}
+	return attrs
+}
@@ -0,0 +1,94 @@
+package go_redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	go_test_ "github.com/pefish/go-test"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	results := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		results[attr.Key] = attr.Value
+	}
+	return results
+}
+
+func TestTracing(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	instance.EnableTracing(&TracingOptions{
+		TracerProvider: provider,
+		WithKeys:       true,
+		RedactKey: func(key string) string {
+			if prefix, _, ok := strings.Cut(key, ":"); ok {
+				return prefix + ":*"
+			}
+			return key
+		},
+	})
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	view := instance.WithContext(ctx)
+	go_test_.Equal(t, nil, view.String.Set("user:123", "token", 0))
+	_, err := view.List.Len("user:123")
+	go_test_.NotEqual(t, nil, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	go_test_.Equal(t, 3, len(spans))
+
+	set := spans[0]
+	go_test_.Equal(t, "string.set", set.Name())
+	go_test_.Equal(t, parent.SpanContext().SpanID(), set.Parent().SpanID())
+	attrs := spanAttributes(set)
+	go_test_.Equal(t, "redis", attrs["db.system"].AsString())
+	go_test_.Equal(t, "SET", attrs["db.operation"].AsString())
+	go_test_.Equal(t, int64(0), attrs["db.redis.database_index"].AsInt64())
+	go_test_.Equal(t, "127.0.0.1", attrs["net.peer.name"].AsString())
+	go_test_.Equal(t, []string{"user:*"}, attrs["db.redis.keys"].AsStringSlice())
+
+	llen := spans[1]
+	go_test_.Equal(t, "list.len", llen.Name())
+	go_test_.Equal(t, codes.Error, llen.Status().Code)
+
+	// 不带 key 名
+	instance.EnableTracing(&TracingOptions{TracerProvider: provider})
+	go_test_.Equal(t, nil, instance.String.Set("user:123", "token", 0))
+	spans = recorder.Ended()
+	last := spans[len(spans)-1]
+	go_test_.Equal(t, false, last.Parent().IsValid())
+	_, ok := spanAttributes(last)["db.redis.keys"]
+	go_test_.Equal(t, false, ok)
+
+	// 调用方取消后命令不再执行
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	go_test_.NotEqual(t, nil, instance.WithContext(canceled).String.Set("user:123", "token", 0))
+}
+
+func TestTracing_Replica(t *testing.T) {
+	instance, servers := newReplicaInstance(t, ReplicaRoundRobin)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	instance.EnableTracing(&TracingOptions{TracerProvider: provider})
+
+	_, err := instance.String.Get("where")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, nil, instance.String.Set("written", "1", 0))
+
+	spans := recorder.Ended()
+	go_test_.Equal(t, 2, len(spans))
+	// 读命令转发到了第一个副本，写命令在主库
+	replicaPort := int64(servers[1].Server().Addr().Port)
+	primaryPort := int64(servers[0].Server().Addr().Port)
+	go_test_.Equal(t, replicaPort, spanAttributes(spans[0])["net.peer.port"].AsInt64())
+	go_test_.Equal(t, primaryPort, spanAttributes(spans[1])["net.peer.port"].AsInt64())
+}
@@ -0,0 +1,190 @@
+package go_redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 下面是各类型的泛型读写函数，T 支持的类型见 formatValue。
+// key（field、元素）不存在时返回零值，需要区分的话用对应的 Ok 函数
+
+// 获取指定 key 的值并转换成 T
+func GetAs[T any](t *StringType, key string) (T, error) {
+	result, _, err := GetAsOk[T](t, key)
+	return result, err
+}
+
+func GetAsOk[T any](t *StringType, key string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.GetOk(key)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := parseValue[T](str)
+	if err != nil {
+		return zero, true, errors.Wrapf(err, "<key: %s> string <%s> to %T failed.", key, str, zero)
+	}
+	return result, true, nil
+}
+
+// 设置指定 key 的值
+func SetAs[T any](t *StringType, key string, value T, expiration time.Duration) error {
+	str, err := formatValue(value)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.Set(key, str, expiration)
+}
+
+// 获取存储在哈希表中指定字段的值并转换成 T
+func HGetAs[T any](t *HashType, key, field string) (T, error) {
+	result, _, err := HGetAsOk[T](t, key, field)
+	return result, err
+}
+
+func HGetAsOk[T any](t *HashType, key, field string) (result_ T, found_ bool, err_ error) {
+	var zero T
+	str, found, err := t.GetOk(key, field)
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := parseValue[T](str)
+	if err != nil {
+		return zero, true, errors.Wrapf(err, "<key: %s, field: %s> string <%s> to %T failed.", key, field, str, zero)
+	}
+	return result, true, nil
+}
+
+// 将哈希表 key 中的字段 field 的值设为 value
+func HSetAs[T any](t *HashType, key, field string, value T) error {
+	str, err := formatValue(value)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s, field: %s>", key, field)
+	}
+	return t.Set(key, field, str)
+}
+
+// 将一个或多个值插入到列表头部
+func LPushAs[T any](t *ListType, key string, values ...T) (listLength_ uint64, err_ error) {
+	strs, err := formatValues(values)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.LPush(key, strs...)
+}
+
+// 在列表中添加一个或多个值到列表尾部
+func RPushAs[T any](t *ListType, key string, values ...T) (listLength_ uint64, err_ error) {
+	strs, err := formatValues(values)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "<key: %s>", key)
+	}
+	return t.RPush(key, strs...)
+}
+
+// 移出并获取列表的第一个元素
+func LPopAs[T any](t *ListType, key string) (T, error) {
+	result, _, err := LPopAsOk[T](t, key)
+	return result, err
+}
+
+func LPopAsOk[T any](t *ListType, key string) (result_ T, found_ bool, err_ error) {
+	str, found, err := t.LPopOk(key)
+	return parseListElement[T](fmt.Sprintf("<key: %s>", key), str, found, err)
+}
+
+// 移除列表的最后一个元素，返回值为移除的元素
+func RPopAs[T any](t *ListType, key string) (T, error) {
+	result, _, err := RPopAsOk[T](t, key)
+	return result, err
+}
+
+func RPopAsOk[T any](t *ListType, key string) (result_ T, found_ bool, err_ error) {
+	str, found, err := t.RPopOk(key)
+	return parseListElement[T](fmt.Sprintf("<key: %s>", key), str, found, err)
+}
+
+// 根据索引获取列表中的元素
+func LIndexAs[T any](t *ListType, key string, index int) (T, error) {
+	result, _, err := LIndexAsOk[T](t, key, index)
+	return result, err
+}
+
+func LIndexAsOk[T any](t *ListType, key string, index int) (result_ T, found_ bool, err_ error) {
+	str, found, err := t.GetOk(key, index)
+	return parseListElement[T](fmt.Sprintf("<key: %s> <index: %d>", key, index), str, found, err)
+}
+
+// 根据索引设置列表中的元素，key 不存在时报错
+func LSetAs[T any](t *ListType, key string, index int, value T) error {
+	str, err := formatValue(value)
+	if err != nil {
+		return errors.WithMessagef(err, "<key: %s> <index: %d>", key, index)
+	}
+	return t.Set(key, index, str)
+}
+
+// 获取列表指定范围内的元素，key 不存在返回 nil,nil
+func RangeAs[T any](t *ListType, key string, start int64, stop int64) ([]T, error) {
+	strs, err := t.Range(key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+	if len(strs) == 0 {
+		return nil, nil
+	}
+	results := make([]T, 0, len(strs))
+	for i, str := range strs {
+		result, err := parseValue[T](str)
+		if err != nil {
+			return nil, errors.Wrapf(err, "<key: %s> <index: %d> string <%s> to %T failed.", key, t.rangeStartIndex(key, start)+int64(i), str, result)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// 把 LRANGE 的 start 换算成实际的起始下标（负数从尾部算起，越界时为 0），用于错误信息。
+// 需要额外读一次列表长度，只在出错时调用，读取失败时原样返回 start
+func (t *ListType) rangeStartIndex(key string, start int64) int64 {
+	if start >= 0 {
+		return start
+	}
+	length, err := t.Len(key)
+	if err != nil {
+		return start
+	}
+	return max(int64(length)+start, 0)
+}
+
+// 同 ListType.rangeStartIndex，用于 ZRANGE、ZREVRANGE
+func (t *OrderSetType) rangeStartIndex(key string, start int64) int64 {
+	if start >= 0 {
+		return start
+	}
+	length, err := t.TotalCount(key)
+	if err != nil {
+		return start
+	}
+	return max(length+start, 0)
+}
+
+// 获取列表中所有的元素，key 不存在返回 nil,nil
+func ListAllAs[T any](t *ListType, key string) ([]T, error) {
+	return RangeAs[T](t, key, 0, -1)
+}
+
+// position 是错误信息里的位置描述，例如 <key: xx> <index: 1>
+func parseListElement[T any](position string, str string, found bool, err error) (T, bool, error) {
+	var zero T
+	if err != nil || !found {
+		return zero, false, err
+	}
+	result, err := parseValue[T](str)
+	if err != nil {
+		return zero, true, errors.Wrapf(err, "%s string <%s> to %T failed.", position, str, zero)
+	}
+	return result, true, nil
+}
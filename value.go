@@ -0,0 +1,135 @@
+package go_redis
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 把值转换成 redis 中存储的字符串。
+// 支持 string、[]byte、所有整数、浮点数、bool、time.Duration、Float32Vector（二进制）以及实现了 encoding.TextMarshaler 的类型（包括 time.Time）
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case time.Duration:
+		return v.String(), nil
+	case Float32Vector:
+		return string(EncodeFloat32Vector(v)), nil
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			return ``, errors.Wrapf(err, "%T marshal text failed.", value)
+		}
+		return string(b), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	}
+	return ``, errors.Errorf("unsupported type %T.", value)
+}
+
+// 把 redis 中存储的字符串转换成 T，支持的类型同 formatValue
+func parseValue[T any](str string) (T, error) {
+	var result T
+	if err := parseValueTo(str, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// dst 必须是指针
+func parseValueTo(str string, dst any) error {
+	switch d := dst.(type) {
+	case *string:
+		*d = str
+		return nil
+	case *[]byte:
+		*d = []byte(str)
+		return nil
+	case *time.Duration:
+		r, err := time.ParseDuration(str)
+		if err != nil {
+			// 兼容直接存纳秒数的情况
+			n, err1 := strconv.ParseInt(str, 10, 64)
+			if err1 != nil {
+				return err
+			}
+			r = time.Duration(n)
+		}
+		*d = r
+		return nil
+	case *Float32Vector:
+		r, err := DecodeFloat32Vector([]byte(str))
+		if err != nil {
+			return err
+		}
+		*d = r
+		return nil
+	case encoding.TextUnmarshaler:
+		return d.UnmarshalText([]byte(str))
+	}
+
+	rv := reflect.ValueOf(dst).Elem()
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(str)
+	case reflect.Bool:
+		r, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(r)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r, err := strconv.ParseInt(str, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(r)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		r, err := strconv.ParseUint(str, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(r)
+	case reflect.Float32, reflect.Float64:
+		r, err := strconv.ParseFloat(str, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(r)
+	default:
+		return errors.Errorf("unsupported type %s.", rv.Type())
+	}
+	return nil
+}
+
+func formatValues[T any](values []T) ([]string, error) {
+	results := make([]string, 0, len(values))
+	for i, value := range values {
+		str, err := formatValue(value)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "<index: %d>", i)
+		}
+		results = append(results, str)
+	}
+	return results, nil
+}
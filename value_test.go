@@ -0,0 +1,76 @@
+package go_redis
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	go_test_ "github.com/pefish/go-test"
+)
+
+func testValueRoundTrip[T any](t *testing.T, value T, expectStr string) {
+	str, err := formatValue(value)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, expectStr, str)
+	result, err := parseValue[T](str)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, value, result)
+}
+
+func TestValue_RoundTrip(t *testing.T) {
+	type status int8
+
+	testValueRoundTrip(t, int8(-8), "-8")
+	testValueRoundTrip(t, uint16(65535), "65535")
+	testValueRoundTrip(t, status(3), "3")
+	testValueRoundTrip(t, float32(1.5), "1.5")
+	testValueRoundTrip(t, 0.1, "0.1")
+	testValueRoundTrip(t, true, "true")
+	testValueRoundTrip(t, 90*time.Second, "1m30s")
+	testValueRoundTrip(t, time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), "2024-01-02T03:04:05.000000006Z")
+	testValueRoundTrip(t, netip.MustParseAddr("127.0.0.1"), "127.0.0.1")
+
+	_, err := parseValue[int8]("128")
+	go_test_.NotEqual(t, nil, err)
+	_, err = formatValue(struct{}{})
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestValue_ListAllAs(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	_, err := RPushAs(instance.List, "test_list_as", 1, 2, 3)
+	go_test_.Equal(t, nil, err)
+	results, err := ListAllAs[int32](instance.List, "test_list_as")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []int32{1, 2, 3}, results)
+
+	_, err = instance.List.RPush("test_list_as", "x")
+	go_test_.Equal(t, nil, err)
+	_, err = ListAllAs[int32](instance.List, "test_list_as")
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_list_as> <index: 3> string <x> to int32 failed."))
+}
+
+func TestValue_RangeAsIndex(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	_, err := instance.List.RPush("test_range_as", "1", "2", "x")
+	go_test_.Equal(t, nil, err)
+	_, err = RangeAs[int32](instance.List, "test_range_as", 1, -1)
+	go_test_.Equal(t, true, strings.Contains(err.Error(), "<key: test_range_as> <index: 2> string <x> to int32 failed."))
+}
+
+func TestValue_GetFloat64(t *testing.T) {
+	instance, _ := newMiniRedisInstance(t)
+
+	result, err := instance.String.GetFloat64("test_float_none")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 0.0, result)
+
+	go_test_.Equal(t, nil, instance.String.Set("test_float", "abc", 0))
+	_, err = instance.String.GetFloat64("test_float")
+	_, _, errOk := instance.String.GetFloat64Ok("test_float")
+	go_test_.NotEqual(t, nil, err)
+	go_test_.Equal(t, errOk.Error(), err.Error())
+}
@@ -0,0 +1,188 @@
+package go_redis
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// float32 向量，在哈希表中存储为小端字节序的二进制，和 RediSearch 的 VECTOR TYPE FLOAT32 字段格式一致。
+// 二进制编码只用于哈希表，json.Marshal 时仍是普通的数字数组。
+// 可以直接作为 SetStruct、GetStruct 和 SearchDocument.Decode 的结构体字段：
+//
+//	type Item struct {
+//		Category  string                 `redis:"category" search:"tag"`
+//		Embedding go_redis.Float32Vector `redis:"embedding" search:"vector,algorithm=hnsw,dim=768"`
+//	}
+type Float32Vector []float32
+
+// 编码成小端字节序的二进制
+func EncodeFloat32Vector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+// 解码小端字节序的二进制，长度必须是 4 的倍数
+func DecodeFloat32Vector(data []byte) ([]float32, error) {
+	if len(data)%4 != 0 {
+		return nil, errors.Errorf("vector length %d is not a multiple of 4.", len(data))
+	}
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector, nil
+}
+
+// 把向量以二进制写入哈希表的字段
+func (t *HashType) SetVector(key, field string, vector []float32) error {
+	return t.Set(key, field, string(EncodeFloat32Vector(vector)))
+}
+
+// 读取 SetVector 写入的向量，字段不存在时返回 nil
+func (t *HashType) GetVector(key, field string) ([]float32, error) {
+	result, _, err := t.GetVectorOk(key, field)
+	return result, err
+}
+
+func (t *HashType) GetVectorOk(key, field string) (result_ []float32, found_ bool, err_ error) {
+	str, found, err := t.GetOk(key, field)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	vector, err := DecodeFloat32Vector([]byte(str))
+	if err != nil {
+		return nil, true, errors.WithMessagef(err, "<key: %s, field: %s>", t.prefix.key(key), field)
+	}
+	return vector, true, nil
+}
+
+// 只有向量字段的索引，filters 是用于混合查询的其他字段，可以为空。options 可以为 nil
+//
+//	err := instance.Search.CreateVectorIndex("items", "embedding", go_redis.VectorFieldOptions{
+//		Algorithm: go_redis.VectorHNSW,
+//		Dim:       768,
+//		M:         16,
+//	}, []go_redis.SearchField{{Name: "category", Type: go_redis.SearchTag}}, &go_redis.IndexOptions{Prefixes: []string{"item:"}})
+func (t *SearchType) CreateVectorIndex(index string, field string, vector VectorFieldOptions, filters []SearchField, options *IndexOptions) error {
+	fields := make([]SearchField, 0, len(filters)+1)
+	fields = append(fields, SearchField{
+		Name:   field,
+		Type:   SearchVector,
+		Vector: &vector,
+	})
+	fields = append(fields, filters...)
+	return t.CreateIndex(index, fields, options)
+}
+
+// KNN 结果中距离的字段名
+const vectorDistanceField = "__vector_distance"
+
+// KNN 向量相似度查询构造器，结果按距离从近到远排序
+//
+//	matches, err := instance.Search.KNN("items", "embedding", embedding, 10).
+//		Filter("@category:{shoes} @price:[0 100]").
+//		Return("category").
+//		Exec()
+type KNNQuery struct {
+	t         *SearchType
+	index     string
+	field     string
+	vector    []float32
+	k         int
+	filter    string
+	efRuntime int
+	returns   []string
+	err       error
+}
+
+type VectorMatch struct {
+	Id       string  // 哈希表的 key，去掉了 t 的前缀
+	Distance float64 // 按索引的 DISTANCE_METRIC 计算，越小越相似（COSINE 是 1 - 余弦相似度）
+	Fields   map[string]string
+}
+
+func (t *SearchType) KNN(index string, field string, vector []float32, k int) *KNNQuery {
+	q := &KNNQuery{
+		t:      t,
+		index:  index,
+		field:  field,
+		vector: vector,
+		k:      k,
+	}
+	if k <= 0 {
+		q.err = errors.Errorf("<k: %d> must be positive.", k)
+	}
+	if len(vector) == 0 {
+		q.err = errors.New("vector is empty.")
+	}
+	return q
+}
+
+// 混合查询，先用查询语法过滤再在结果中找最近的 k 个，例如 "@category:{shoes}"
+func (q *KNNQuery) Filter(filter string) *KNNQuery {
+	q.filter = filter
+	return q
+}
+
+// HNSW 查询时的候选列表大小，越大越精确但越慢，默认使用索引的 EF_RUNTIME
+func (q *KNNQuery) EFRuntime(efRuntime int) *KNNQuery {
+	q.efRuntime = efRuntime
+	return q
+}
+
+// 结果中要返回的字段，默认只返回 key 和距离
+func (q *KNNQuery) Return(fields ...string) *KNNQuery {
+	q.returns = append(q.returns, fields...)
+	return q
+}
+
+func (q *KNNQuery) query() string {
+	filter := strings.TrimSpace(q.filter)
+	if filter == "" {
+		filter = "*"
+	} else {
+		filter = "(" + filter + ")"
+	}
+	knn := "KNN $knn_k @" + q.field + " $knn_vector"
+	if q.efRuntime > 0 {
+		knn += " EF_RUNTIME " + strconv.Itoa(q.efRuntime)
+	}
+	return filter + "=>[" + knn + " AS " + vectorDistanceField + "]"
+}
+
+func (q *KNNQuery) Exec() ([]VectorMatch, error) {
+	if q.err != nil {
+		return nil, errors.WithMessagef(q.err, "<index: %s>", q.t.prefix.key(q.index))
+	}
+	result, err := q.t.Query(q.index, q.query()).
+		Return(append([]string{vectorDistanceField}, q.returns...)...).
+		SortBy(vectorDistanceField, true).
+		Limit(0, q.k).
+		Param("knn_k", q.k).
+		Param("knn_vector", EncodeFloat32Vector(q.vector)).
+		Exec()
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]VectorMatch, 0, len(result.Docs))
+	for _, doc := range result.Docs {
+		distance, err := strconv.ParseFloat(doc.Fields[vectorDistanceField], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "<id: %s> parse distance failed", doc.Id)
+		}
+		delete(doc.Fields, vectorDistanceField)
+		matches = append(matches, VectorMatch{
+			Id:       doc.Id,
+			Distance: distance,
+			Fields:   doc.Fields,
+		})
+	}
+	return matches, nil
+}
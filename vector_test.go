@@ -0,0 +1,142 @@
+package go_redis
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	go_test_ "github.com/pefish/go-test"
+)
+
+type vectorItem struct {
+	Category  string        `redis:"category" search:"tag"`
+	Embedding Float32Vector `redis:"embedding" search:"vector,algorithm=hnsw,dim=3,distance=l2,m=8,ef_construction=100"`
+}
+
+func TestFloat32Vector_Encode(t *testing.T) {
+	data := EncodeFloat32Vector([]float32{1, -2.5})
+	go_test_.Equal(t, []byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x20, 0xc0}, data)
+	vector, err := DecodeFloat32Vector(data)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []float32{1, -2.5}, vector)
+
+	special := []float32{0, float32(math.Inf(-1)), math.MaxFloat32, math.SmallestNonzeroFloat32}
+	vector, err = DecodeFloat32Vector(EncodeFloat32Vector(special))
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, special, vector)
+
+	_, err = DecodeFloat32Vector([]byte{1, 2, 3})
+	go_test_.NotEqual(t, nil, err)
+	vector, err = DecodeFloat32Vector(nil)
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 0, len(vector))
+}
+
+func TestHashType_Vector(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+
+	go_test_.Equal(t, nil, instance.Hash.SetVector("item:1", "embedding", []float32{0.1, 0.2, 0.3}))
+	raw := m.HGet("item:1", "embedding")
+	go_test_.Equal(t, 12, len(raw))
+	vector, err := instance.Hash.GetVector("item:1", "embedding")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, []float32{0.1, 0.2, 0.3}, vector)
+
+	_, found, err := instance.Hash.GetVectorOk("item:1", "missing")
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, false, found)
+	go_test_.Equal(t, nil, instance.Hash.Set("item:1", "broken", "abc"))
+	_, err = instance.Hash.GetVector("item:1", "broken")
+	go_test_.NotEqual(t, nil, err)
+
+	// 结构体字段以二进制存储，不是 JSON
+	go_test_.Equal(t, nil, instance.Hash.SetStruct("item:2", vectorItem{Category: "shoes", Embedding: Float32Vector{1, 2, 3}}))
+	go_test_.Equal(t, string(EncodeFloat32Vector([]float32{1, 2, 3})), m.HGet("item:2", "embedding"))
+	var item vectorItem
+	go_test_.Equal(t, nil, instance.Hash.GetStruct("item:2", &item))
+	go_test_.Equal(t, vectorItem{Category: "shoes", Embedding: Float32Vector{1, 2, 3}}, item)
+
+	// 二进制只用于哈希表，JSON 中仍是数字数组
+	data, err := json.Marshal(vectorItem{Category: "shoes", Embedding: Float32Vector{1, 2.5}})
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, `{"Category":"shoes","Embedding":[1,2.5]}`, string(data))
+}
+
+func TestSearchType_CreateVectorIndex(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	fake := registerFakeSearch(t, m)
+
+	go_test_.Equal(t, nil, instance.Search.CreateIndex("items", vectorItem{}, &IndexOptions{Prefixes: []string{"item:"}}))
+	go_test_.Equal(t, "items ON HASH PREFIX 1 item: SCHEMA category TAG "+
+		"embedding VECTOR HNSW 10 TYPE FLOAT32 DIM 3 DISTANCE_METRIC L2 M 8 EF_CONSTRUCTION 100", fake.args("FT.CREATE"))
+
+	go_test_.Equal(t, nil, instance.Search.CreateVectorIndex("flat", "embedding", VectorFieldOptions{
+		Dim:        768,
+		Distance:   VectorIP,
+		InitialCap: 1000,
+		BlockSize:  512,
+	}, []SearchField{{Name: "category", Type: SearchTag}}, nil))
+	go_test_.Equal(t, "flat ON HASH SCHEMA embedding VECTOR FLAT 10 TYPE FLOAT32 DIM 768 DISTANCE_METRIC IP "+
+		"INITIAL_CAP 1000 BLOCK_SIZE 512 category TAG", fake.args("FT.CREATE"))
+
+	go_test_.Equal(t, nil, instance.Search.CreateVectorIndex("hnsw", "embedding", VectorFieldOptions{
+		Algorithm: VectorHNSW,
+		Dim:       4,
+		EFRuntime: 20,
+	}, nil, nil))
+	go_test_.Equal(t, "hnsw ON HASH SCHEMA embedding VECTOR HNSW 8 TYPE FLOAT32 DIM 4 DISTANCE_METRIC COSINE EF_RUNTIME 20", fake.args("FT.CREATE"))
+
+	err := instance.Search.CreateVectorIndex("bad", "embedding", VectorFieldOptions{Dim: 4, M: 16}, nil, nil)
+	go_test_.NotEqual(t, nil, err)
+	err = instance.Search.CreateVectorIndex("bad", "embedding", VectorFieldOptions{Algorithm: VectorHNSW, Dim: 4, BlockSize: 16}, nil, nil)
+	go_test_.NotEqual(t, nil, err)
+	err = instance.Search.CreateVectorIndex("bad", "embedding", VectorFieldOptions{Algorithm: "IVF", Dim: 4}, nil, nil)
+	go_test_.NotEqual(t, nil, err)
+}
+
+func TestSearchType_KNN(t *testing.T) {
+	instance, m := newMiniRedisInstance(t)
+	fake := registerFakeSearch(t, m)
+	shop := instance.WithPrefix("shop:")
+	go_test_.Equal(t, nil, shop.Search.CreateIndex("items", vectorItem{}, &IndexOptions{Prefixes: []string{"item:"}}))
+	items := map[string]vectorItem{
+		"item:1": {Category: "shoes", Embedding: Float32Vector{0, 0, 0}},
+		"item:2": {Category: "shoes", Embedding: Float32Vector{1, 0, 0}},
+		"item:3": {Category: "hats", Embedding: Float32Vector{0.1, 0, 0}},
+		"item:4": {Category: "shoes", Embedding: Float32Vector{3, 0, 0}},
+	}
+	for key, item := range items {
+		go_test_.Equal(t, nil, shop.Hash.SetStruct(key, item))
+	}
+
+	matches, err := shop.Search.KNN("items", "embedding", []float32{0.2, 0, 0}, 2).Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, "shop:items *=>[KNN $knn_k @embedding $knn_vector AS __vector_distance] RETURN 1 __vector_distance "+
+		"SORTBY __vector_distance ASC LIMIT 0 2 PARAMS 4 knn_k 2 knn_vector "+string(EncodeFloat32Vector([]float32{0.2, 0, 0}))+" DIALECT 2",
+		fake.args("FT.SEARCH"))
+	go_test_.Equal(t, 2, len(matches))
+	go_test_.Equal(t, "item:3", matches[0].Id)
+	go_test_.Equal(t, true, math.Abs(matches[0].Distance-0.01) < 1e-6)
+	go_test_.Equal(t, "item:1", matches[1].Id)
+	go_test_.Equal(t, map[string]string{}, matches[1].Fields)
+
+	// 混合查询：先过滤类别再找最近的
+	matches, err = shop.Search.KNN("items", "embedding", []float32{0.2, 0, 0}, 5).
+		Filter("@category:{shoes}").
+		EFRuntime(50).
+		Return("category").
+		Exec()
+	go_test_.Equal(t, nil, err)
+	go_test_.Equal(t, 3, len(matches))
+	ids := []string{matches[0].Id, matches[1].Id, matches[2].Id}
+	go_test_.Equal(t, []string{"item:1", "item:2", "item:4"}, ids)
+	go_test_.Equal(t, true, math.Abs(matches[1].Distance-0.64) < 1e-6)
+	go_test_.Equal(t, map[string]string{"category": "shoes"}, matches[2].Fields)
+	go_test_.Equal(t, "(@category:{shoes})=>[KNN $knn_k @embedding $knn_vector EF_RUNTIME 50 AS __vector_distance]",
+		fake.received["FT.SEARCH"][1])
+
+	_, err = shop.Search.KNN("items", "embedding", []float32{0.2, 0, 0}, 0).Exec()
+	go_test_.NotEqual(t, nil, err)
+	_, err = shop.Search.KNN("items", "embedding", nil, 3).Exec()
+	go_test_.NotEqual(t, nil, err)
+}